@@ -0,0 +1,148 @@
+package mcgotocol
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"github.com/NaymDev/mcgotocol/chat"
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/encryption"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state"
+	"reflect"
+)
+
+var ErrVerifyTokenMismatch = errors.New("verify token mismatch")
+
+// SetAutoState lets the connection drive the protocol state machine itself:
+//
+//   - ServerHandshake switches to Status or Login depending on NextState,
+//   - ClientSetCompression enables compression with its threshold,
+//   - ClientLoginSuccess switches to Play,
+//   - ServerEncryptionResponse enables encryption on the server side if a key
+//     was set with SetEncryptionKey.
+//
+// Transitions happen after the packet was read or written. Writing a packet
+// that is not registered in the current state fails with
+// *state.UnexpectedPacket; a *proto.RawPacket is always allowed.
+//
+// Clients have to call EnableEncryption themselves after writing the
+// Encryption Response since only they know the plain shared secret.
+func (c *Connection) SetAutoState(enabled bool) {
+	c.autoState = enabled
+}
+
+// SetEncryptionKey sets the server keypair used to decrypt the Encryption
+// Response when auto state is enabled. The verify token of the written
+// ClientEncryptionRequest is checked as well.
+func (c *Connection) SetEncryptionKey(key *rsa.PrivateKey) {
+	c.encryptionKey = key
+}
+
+// SharedSecret returns the secret negotiated during the automatic encryption
+// handshake, e.g. to compute encryption.ServerHash. It is nil until then.
+func (c *Connection) SharedSecret() []byte {
+	return c.sharedSecret
+}
+
+func (c *Connection) outboundRegistry() *state.PacketRegistry {
+	if c.outbound() == proto.ServerBound {
+		return c.Registry().ServerBound
+	}
+	return c.Registry().ClientBound
+}
+
+func (c *Connection) checkOutbound(p proto.Packet) error {
+	if !c.autoState {
+		return nil
+	}
+	if _, ok := p.(*proto.RawPacket); ok {
+		return nil
+	}
+	registry := c.outboundRegistry()
+	if registry.Contains(p) {
+		return nil
+	}
+	return &state.UnexpectedPacket{
+		PacketID: p.ID(),
+		Type:     reflect.TypeOf(p).String(),
+		State:    registry.State,
+	}
+}
+
+// trackOutbound is called with writeMu held after p was written or queued.
+func (c *Connection) trackOutbound(p proto.Packet) {
+	if !c.autoState {
+		return
+	}
+	switch p := p.(type) {
+	case *packet.ServerHandshake:
+		c.switchToIntent(p.NextState)
+	case *packet.ClientSetCompression:
+		c.SetCompressionThreshold(int(p.Threshold))
+	case *packet.ClientEncryptionRequest:
+		c.verifyToken = p.VerifyToken
+	case *packet.ClientLoginSuccess:
+		c.SetState(state.Play)
+	}
+}
+
+// trackInbound is called after p was decoded and passed to the inbound
+// interceptors.
+func (c *Connection) trackInbound(p proto.Packet) error {
+	if !c.autoState {
+		return nil
+	}
+	switch p := p.(type) {
+	case *packet.ServerHandshake:
+		c.switchToIntent(p.NextState)
+	case *packet.ClientSetCompression:
+		c.SetCompressionThreshold(int(p.Threshold))
+	case *packet.ClientLoginSuccess:
+		c.SetState(state.Play)
+	case *packet.ServerEncryptionResponse:
+		if c.encryptionKey == nil {
+			return nil
+		}
+		return c.acceptEncryption(p)
+	}
+	return nil
+}
+
+func (c *Connection) switchToIntent(intent codec.VarInt) {
+	switch packet.HandshakeIntent(intent) {
+	case packet.StatusHandshakeIntent:
+		c.SetState(state.Status)
+	case packet.LoginHandshakeIntent:
+		c.SetState(state.Login)
+	}
+}
+
+func (c *Connection) acceptEncryption(p *packet.ServerEncryptionResponse) error {
+	c.writeMu.Lock()
+	expected := c.verifyToken
+	c.writeMu.Unlock()
+
+	token, err := encryption.Decrypt(c.encryptionKey, p.VerifyToken)
+	if err != nil {
+		return fmt.Errorf("decrypting verify token: %w", err)
+	}
+	if !bytes.Equal(token, expected) {
+		return ErrVerifyTokenMismatch
+	}
+	secret, err := encryption.Decrypt(c.encryptionKey, p.SharedSecret)
+	if err != nil {
+		return fmt.Errorf("decrypting shared secret: %w", err)
+	}
+	if len(secret) != encryption.SharedSecretSize {
+		_ = c.Kick(codec.NewChat(chat.Text("Invalid shared secret").Build()))
+		return encryption.ErrInvalidSecretLength
+	}
+	if err := c.EnableEncryption(secret); err != nil {
+		return err
+	}
+	c.sharedSecret = secret
+	return nil
+}
@@ -0,0 +1,83 @@
+package mcgotocol
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/encryption"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state"
+)
+
+type readWriter struct {
+	io.Reader
+	io.Writer
+}
+
+func TestAcceptEncryptionRejectsSecretLength(t *testing.T) {
+	state.InitRegistries()
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := []byte{1, 2, 3, 4}
+	secret, _ := encryption.Encrypt(&key.PublicKey, make([]byte, 32))
+	encToken, _ := encryption.Encrypt(&key.PublicKey, token)
+	frame, err := codec.MarshalPacket(&packet.ServerEncryptionResponse{SharedSecret: secret, VerifyToken: encToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	c := NewConnection(readWriter{bytes.NewReader(frame), out}, state.Login)
+	c.SetAutoState(true)
+	c.SetEncryptionKey(key)
+	if err := c.WritePacket(&packet.ClientEncryptionRequest{VerifyToken: token}); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+
+	if _, err := c.ReadPacket(); err != encryption.ErrInvalidSecretLength {
+		t.Fatalf("ReadPacket error = %v, want ErrInvalidSecretLength", err)
+	}
+	if out.Len() == 0 {
+		t.Fatal("no Login Disconnect was sent")
+	}
+	if err := c.EnableEncryption(make([]byte, 24)); err != encryption.ErrInvalidSecretLength {
+		t.Fatalf("EnableEncryption error = %v", err)
+	}
+}
+
+func TestInboundInterceptorSeesReadState(t *testing.T) {
+	state.InitRegistries()
+	frame, err := codec.MarshalPacket(&packet.ServerHandshake{
+		ProtocolVersion: 47,
+		ServerAddress:   "localhost",
+		ServerPort:      25565,
+		NextState:       codec.VarInt(packet.LoginHandshakeIntent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConnection(readWriter{bytes.NewReader(frame), io.Discard}, state.Handshake)
+	c.SetAutoState(true)
+	var seen, current *state.Registry
+	c.AddInboundInterceptor(func(c *Connection, registry *state.Registry, p proto.Packet) ([]proto.Packet, error) {
+		seen, current = registry, c.Registry()
+		return nil, nil
+	})
+	// the dropped handshake leaves nothing to return
+	if _, err := c.ReadPacket(); err == nil {
+		t.Fatal("ReadPacket returned a packet after the interceptor dropped it")
+	}
+	if seen != state.Handshake || current != state.Handshake {
+		t.Fatalf("interceptor ran in %v (connection in %v), want Handshake", seen.State, current.State)
+	}
+	if c.Registry() != state.Login {
+		t.Fatalf("state after handshake = %v, want Login", c.Registry().State)
+	}
+}
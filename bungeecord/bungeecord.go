@@ -0,0 +1,80 @@
+package bungeecord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/profile"
+	"github.com/google/uuid"
+	"net"
+	"strings"
+)
+
+const separator = "\x00"
+
+var ErrNotForwarded = errors.New("handshake address does not contain BungeeCord forwarding data")
+
+// ForwardedData is what BungeeCord appends to ServerHandshake.ServerAddress
+// when IP forwarding is enabled:
+//
+//	host \x00 client IP \x00 undashed UUID [\x00 properties JSON]
+type ForwardedData struct {
+	Host       string
+	ClientIP   string
+	UUID       uuid.UUID
+	Properties []profile.Property
+}
+
+// Parse splits a forwarded handshake address.
+func Parse(address string) (*ForwardedData, error) {
+	parts := strings.SplitN(address, separator, 4)
+	if len(parts) < 3 {
+		return nil, ErrNotForwarded
+	}
+
+	if net.ParseIP(parts[1]) == nil {
+		return nil, fmt.Errorf("%w: invalid client IP %q", ErrNotForwarded, parts[1])
+	}
+	id, err := uuid.Parse(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid UUID: %v", ErrNotForwarded, err)
+	}
+
+	data := &ForwardedData{
+		Host:     parts[0],
+		ClientIP: parts[1],
+		UUID:     id,
+	}
+	if len(parts) == 4 && parts[3] != "" {
+		if err := json.Unmarshal([]byte(parts[3]), &data.Properties); err != nil {
+			return nil, fmt.Errorf("%w: invalid properties: %v", ErrNotForwarded, err)
+		}
+	}
+	return data, nil
+}
+
+// Encode builds the forwarded handshake address, e.g. for a proxy.
+func (f *ForwardedData) Encode() (string, error) {
+	parts := []string{
+		f.Host,
+		f.ClientIP,
+		strings.ReplaceAll(f.UUID.String(), "-", ""),
+	}
+	if len(f.Properties) > 0 {
+		props, err := json.Marshal(f.Properties)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, string(props))
+	}
+	return strings.Join(parts, separator), nil
+}
+
+// LoginSuccess returns the login success packet for the forwarded identity.
+func (f *ForwardedData) LoginSuccess(username string) *packet.ClientLoginSuccess {
+	return &packet.ClientLoginSuccess{
+		UUID:     f.UUID.String(),
+		Username: username,
+	}
+}
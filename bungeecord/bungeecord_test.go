@@ -0,0 +1,91 @@
+package bungeecord
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/NaymDev/mcgotocol/profile"
+	"github.com/google/uuid"
+)
+
+var notch = uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+
+func TestRoundTrip(t *testing.T) {
+	signature := "c2lnbmF0dXJl"
+	tests := []struct {
+		name    string
+		data    ForwardedData
+		address string
+	}{
+		{
+			"without properties",
+			ForwardedData{Host: "mc.example.com", ClientIP: "203.0.113.7", UUID: notch},
+			"mc.example.com\x00203.0.113.7\x00069a79f444e94726a5befca90e38aaf5",
+		},
+		{
+			"ipv6",
+			ForwardedData{Host: "localhost", ClientIP: "2001:db8::1", UUID: notch},
+			"localhost\x002001:db8::1\x00069a79f444e94726a5befca90e38aaf5",
+		},
+		{
+			"with properties",
+			ForwardedData{
+				Host:     "localhost",
+				ClientIP: "127.0.0.1",
+				UUID:     notch,
+				Properties: []profile.Property{
+					{Name: "textures", Value: "dGV4dHVyZXM=", Signature: &signature},
+				},
+			},
+			"localhost\x00127.0.0.1\x00069a79f444e94726a5befca90e38aaf5\x00" +
+				`[{"name":"textures","value":"dGV4dHVyZXM=","signature":"c2lnbmF0dXJl"}]`,
+		},
+	}
+	for _, tt := range tests {
+		address, err := tt.data.Encode()
+		if err != nil || address != tt.address {
+			t.Errorf("%s: Encode = %q, %v, want %q", tt.name, address, err, tt.address)
+		}
+		got, err := Parse(tt.address)
+		if err != nil {
+			t.Errorf("%s: Parse: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.data) {
+			t.Errorf("%s: Parse = %+v, want %+v", tt.name, *got, tt.data)
+		}
+	}
+}
+
+func TestParseDashedUUIDAndEmptyProperties(t *testing.T) {
+	got, err := Parse("localhost\x00127.0.0.1\x00069a79f4-44e9-4726-a5be-fca90e38aaf5\x00")
+	if err != nil || got.UUID != notch || got.Properties != nil {
+		t.Fatalf("Parse = %+v, %v", got, err)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for name, address := range map[string]string{
+		"plain host":      "localhost",
+		"two parts":       "localhost\x00127.0.0.1",
+		"bad IP":          "localhost\x00not-an-ip\x00069a79f444e94726a5befca90e38aaf5",
+		"bad UUID":        "localhost\x00127.0.0.1\x00069a79f444e9",
+		"bad JSON":        "localhost\x00127.0.0.1\x00069a79f444e94726a5befca90e38aaf5\x00[{",
+		"extra part":      "localhost\x00127.0.0.1\x00069a79f444e94726a5befca90e38aaf5\x00[]\x00x",
+		"properties type": "localhost\x00127.0.0.1\x00069a79f444e94726a5befca90e38aaf5\x00{}",
+	} {
+		if data, err := Parse(address); !errors.Is(err, ErrNotForwarded) {
+			t.Errorf("%s: Parse = %+v, %v, want ErrNotForwarded", name, data, err)
+		}
+	}
+}
+
+func TestLoginSuccess(t *testing.T) {
+	data := &ForwardedData{Host: "localhost", ClientIP: "127.0.0.1", UUID: notch}
+	p := data.LoginSuccess("Notch")
+	// Login Success carries the dashed form
+	if p.UUID != "069a79f4-44e9-4726-a5be-fca90e38aaf5" || p.Username != "Notch" {
+		t.Fatalf("LoginSuccess = %+v", p)
+	}
+}
@@ -0,0 +1,200 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/NaymDev/mcgotocol"
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state"
+	"github.com/NaymDev/mcgotocol/state/states"
+	"io"
+	"sync"
+	"time"
+)
+
+// File layout:
+//
+//	magic "MCCAP" | version byte | start time (int64 unix nanos)
+//	entries: delta nanos (VarLong) | direction byte | state byte | length (VarInt) | packet ID + data
+//
+// Entries are appended as they happen, so a capture that was cut off is
+// readable up to its last complete entry.
+const (
+	magic   = "MCCAP"
+	version = 1
+)
+
+var ErrInvalidCapture = errors.New("not a packet capture")
+
+// Entry is a single recorded packet. Data holds the uncompressed packet ID
+// and data.
+type Entry struct {
+	Time      time.Time
+	Direction proto.Direction
+	State     states.State
+	Data      []byte
+}
+
+// PacketID returns the ID at the start of Data.
+func (e *Entry) PacketID() (int32, error) {
+	id, err := codec.ReadVarInt(bytes.NewReader(e.Data))
+	return int32(id), err
+}
+
+// Decode decodes the entry through the registry of its state and direction.
+func (e *Entry) Decode() (proto.Packet, error) {
+	registry := state.ForState(e.State)
+	if registry == nil {
+		return nil, fmt.Errorf("unknown state %d", e.State)
+	}
+	packets := registry.ServerBound
+	if e.Direction == proto.ClientBound {
+		packets = registry.ClientBound
+	}
+
+	br := bytes.NewReader(e.Data)
+	id, err := codec.ReadVarInt(br)
+	if err != nil {
+		return nil, err
+	}
+	return packets.Decode(int32(id), br)
+}
+
+// Writer appends entries to a capture. It is safe for concurrent use.
+type Writer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	last time.Time
+	err  error
+}
+
+func NewWriter(w io.Writer, start time.Time) (*Writer, error) {
+	header := make([]byte, 0, len(magic)+9)
+	header = append(header, magic...)
+	header = append(header, version)
+	header = binary.BigEndian.AppendUint64(header, uint64(start.UnixNano()))
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, last: start}, nil
+}
+
+func (w *Writer) WriteEntry(e Entry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+
+	delta := e.Time.Sub(w.last)
+	if delta < 0 {
+		delta = 0
+	}
+	w.last = w.last.Add(delta)
+
+	buf := &bytes.Buffer{}
+	codec.WriteVarLong(buf, codec.VarLong(delta))
+	buf.WriteByte(byte(e.Direction))
+	buf.WriteByte(byte(e.State))
+	codec.WriteVarInt(buf, codec.VarInt(len(e.Data)))
+	buf.Write(e.Data)
+
+	_, w.err = w.w.Write(buf.Bytes())
+	return w.err
+}
+
+// Err returns the first write error, e.g. of a Writer used through Attach.
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Attach records every packet read or written by conn from now on into w.
+func Attach(conn *mcgotocol.Connection, w io.Writer) (*Writer, error) {
+	cw, err := NewWriter(w, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	conn.AddFrameHook(func(direction proto.Direction, s states.State, data []byte) {
+		cw.WriteEntry(Entry{
+			Time:      time.Now(),
+			Direction: direction,
+			State:     s,
+			Data:      data,
+		})
+	})
+	return cw, nil
+}
+
+type Reader struct {
+	r     *bufio.Reader
+	start time.Time
+	last  time.Time
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic)+9)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, ErrInvalidCapture
+	}
+	if header[len(magic)] != version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCapture, header[len(magic)])
+	}
+	start := time.Unix(0, int64(binary.BigEndian.Uint64(header[len(magic)+1:])))
+	return &Reader{r: br, start: start, last: start}, nil
+}
+
+// Start returns the time the capture was started.
+func (r *Reader) Start() time.Time {
+	return r.start
+}
+
+// Next returns the next entry or io.EOF at the end of the capture.
+func (r *Reader) Next() (*Entry, error) {
+	delta, err := codec.ReadVarLong(r.r)
+	if err != nil {
+		return nil, err
+	}
+	var meta [2]byte
+	if _, err := io.ReadFull(r.r, meta[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	length, err := codec.ReadVarInt(r.r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	// entries are single packets, so a corrupted length must not make us
+	// allocate more than the largest frame
+	if length < 0 || length > codec.DefaultMaxFrameSize {
+		return nil, fmt.Errorf("%w: entry length %d", ErrInvalidCapture, length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	r.last = r.last.Add(time.Duration(delta))
+	return &Entry{
+		Time:      r.last,
+		Direction: proto.Direction(meta[0]),
+		State:     states.State(meta[1]),
+		Data:      data,
+	}, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package capture
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state"
+	"github.com/NaymDev/mcgotocol/state/states"
+)
+
+// handshake of a 1.8 client connecting to localhost:25565 for the status
+var handshake = []byte{0x00, 0x2f, 0x09, 'l', 'o', 'c', 'a', 'l', 'h', 'o', 's', 't', 0x63, 0xdd, 0x01}
+
+func TestRoundTrip(t *testing.T) {
+	start := time.Unix(1700000000, 123456789)
+	entries := []Entry{
+		{start.Add(time.Second), proto.ServerBound, states.HandshakeState, handshake},
+		{start.Add(time.Second), proto.ServerBound, states.StatusState, []byte{0x00}},
+		{start.Add(5 * time.Second), proto.ClientBound, states.StatusState, []byte{0x00, 0x02, '{', '}'}},
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if err := w.WriteEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// an entry from before the previous one is recorded at the same time
+	if err := w.WriteEntry(Entry{start, proto.ServerBound, states.StatusState, []byte{0x01}}); err != nil {
+		t.Fatal(err)
+	}
+	entries = append(entries, Entry{start.Add(5 * time.Second), proto.ServerBound, states.StatusState, []byte{0x01}})
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range entries {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+		if !got.Time.Equal(want.Time) || got.Direction != want.Direction || got.State != want.State || !bytes.Equal(got.Data, want.Data) {
+			t.Errorf("entry %d = %+v, want %+v", i, got, want)
+		}
+		if !r.Start().Equal(start) {
+			t.Errorf("Start after entry %d = %v, want %v", i, r.Start(), start)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next at the end = %v, want io.EOF", err)
+	}
+}
+
+func TestEntryDecode(t *testing.T) {
+	state.InitRegistries()
+	e := Entry{Direction: proto.ServerBound, State: states.HandshakeState, Data: handshake}
+	if id, err := e.PacketID(); err != nil || id != 0x00 {
+		t.Errorf("PacketID = %d, %v", id, err)
+	}
+	p, err := e.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &packet.ServerHandshake{ProtocolVersion: 47, ServerAddress: "localhost", ServerPort: 25565, NextState: 1}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("Decode = %+v, want %+v", p, want)
+	}
+}
+
+func TestReaderRejects(t *testing.T) {
+	header := []byte{'M', 'C', 'C', 'A', 'P', version, 0, 0, 0, 0, 0, 0, 0, 0}
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"magic", []byte("MCCAX\x01\x00\x00\x00\x00\x00\x00\x00\x00"), ErrInvalidCapture},
+		{"version", []byte("MCCAP\x02\x00\x00\x00\x00\x00\x00\x00\x00"), ErrInvalidCapture},
+		{"truncated entry", append(header, 0x00, 0x01, 0x00, 0x05, 0x00), io.ErrUnexpectedEOF},
+		{"huge length", append(header, 0x00, 0x01, 0x00, 0xff, 0xff, 0xff, 0xff, 0x07), ErrInvalidCapture},
+		{"negative length", append(header, 0x00, 0x01, 0x00, 0xff, 0xff, 0xff, 0xff, 0x0f), ErrInvalidCapture},
+	}
+	for _, tt := range tests {
+		r, err := NewReader(bytes.NewReader(tt.data))
+		if err == nil {
+			_, err = r.Next()
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package channels
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/NaymDev/mcgotocol"
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/proto"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	BrandChannel      = "MC|Brand"
+	RegisterChannel   = "REGISTER"
+	UnregisterChannel = "UNREGISTER"
+)
+
+// Limits on REGISTER, the same as those of CraftBukkit 1.8 servers.
+const (
+	MaxPeerChannels  = 128
+	MaxChannelLength = 20
+)
+
+var (
+	ErrTooManyChannels = errors.New("too many plugin channels registered")
+	ErrChannelTooLong  = errors.New("plugin channel name too long")
+)
+
+// Message is a typed plugin message payload.
+type Message interface {
+	Channel() string
+	Encode(io.Writer) error
+	Decode(io.Reader) error
+}
+
+type Handler func(s *Session, data []byte) error
+
+type MessageHandler func(s *Session, msg Message) error
+
+// Router maps channel names to handlers. A single Router is usually shared by
+// all connections.
+type Router struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRouter() *Router {
+	return &Router{handlers: make(map[string]Handler)}
+}
+
+// Handle registers h for the raw payloads of channel.
+func (r *Router) Handle(channel string, h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[channel] = h
+}
+
+// Register decodes payloads of msg.Channel() into a fresh value of msg's type
+// before calling h.
+func (r *Router) Register(msg Message, h MessageHandler) {
+	t := reflect.TypeOf(msg)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	r.Handle(msg.Channel(), func(s *Session, data []byte) error {
+		m := reflect.New(t).Interface().(Message)
+		if err := m.Decode(bytes.NewReader(data)); err != nil {
+			return err
+		}
+		return h(s, m)
+	})
+}
+
+// Channels returns the names of all channels with a handler, sorted.
+func (r *Router) Channels() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	channels := make([]string, 0, len(r.handlers))
+	for channel := range r.handlers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+func (r *Router) handler(channel string) Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.handlers[channel]
+}
+
+// Session keeps the plugin channel state of one connection: the channels the
+// peer registered and the brand it announced.
+type Session struct {
+	conn   *mcgotocol.Connection
+	router *Router
+
+	mu     sync.RWMutex
+	remote map[string]struct{}
+	brand  string
+}
+
+func NewSession(conn *mcgotocol.Connection, router *Router) *Session {
+	return &Session{
+		conn:   conn,
+		router: router,
+		remote: make(map[string]struct{}),
+	}
+}
+
+func (s *Session) Conn() *mcgotocol.Connection {
+	return s.conn
+}
+
+// HandlePacket dispatches p if it is a plugin message and reports whether it
+// was one. REGISTER, UNREGISTER and MC|Brand are tracked before the router
+// sees them. A REGISTER that would exceed MaxPeerChannels or names a channel
+// longer than MaxChannelLength is rejected as a whole with an error; the
+// connection should be closed then.
+func (s *Session) HandlePacket(p proto.Packet) (bool, error) {
+	var channel string
+	var data []byte
+	switch p := p.(type) {
+	case *packet.ServerPluginMessage:
+		channel, data = p.Channel, p.Data
+	case *packet.ClientPluginMessage:
+		channel, data = p.Channel, p.Data
+	default:
+		return false, nil
+	}
+
+	switch channel {
+	case RegisterChannel:
+		if err := s.register(splitChannels(data)); err != nil {
+			return true, err
+		}
+	case UnregisterChannel:
+		s.mu.Lock()
+		for _, name := range splitChannels(data) {
+			delete(s.remote, name)
+		}
+		s.mu.Unlock()
+	case BrandChannel:
+		brand, err := codec.ReadString(bytes.NewReader(data))
+		if err != nil {
+			return true, err
+		}
+		s.mu.Lock()
+		s.brand = brand
+		s.mu.Unlock()
+	}
+
+	if h := s.router.handler(channel); h != nil {
+		return true, h(s, data)
+	}
+	return true, nil
+}
+
+func (s *Session) register(names []string) error {
+	for _, name := range names {
+		if utf8.RuneCountInString(name) > MaxChannelLength {
+			return fmt.Errorf("%w: %.32q", ErrChannelTooLong, name)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fresh := make(map[string]struct{})
+	for _, name := range names {
+		if _, ok := s.remote[name]; !ok {
+			fresh[name] = struct{}{}
+		}
+	}
+	if n := len(s.remote) + len(fresh); n > MaxPeerChannels {
+		return fmt.Errorf("%w: %d, at most %d", ErrTooManyChannels, n, MaxPeerChannels)
+	}
+	for name := range fresh {
+		s.remote[name] = struct{}{}
+	}
+	return nil
+}
+
+// Brand returns the brand the peer sent on MC|Brand, e.g. "vanilla".
+func (s *Session) Brand() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.brand
+}
+
+// PeerChannels returns the channels the peer registered, sorted.
+func (s *Session) PeerChannels() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	channels := make([]string, 0, len(s.remote))
+	for channel := range s.remote {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+func (s *Session) PeerRegistered(channel string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.remote[channel]
+	return ok
+}
+
+// Send writes a raw payload on channel in the direction of the peer.
+func (s *Session) Send(channel string, data []byte) error {
+	if s.conn.IsClient() {
+		return s.conn.WritePacket(&packet.ServerPluginMessage{Channel: channel, Data: data})
+	}
+	return s.conn.WritePacket(&packet.ClientPluginMessage{Channel: channel, Data: data})
+}
+
+func (s *Session) SendMessage(msg Message) error {
+	buf := &bytes.Buffer{}
+	if err := msg.Encode(buf); err != nil {
+		return err
+	}
+	return s.Send(msg.Channel(), buf.Bytes())
+}
+
+// RegisterChannels announces all channels of the router to the peer.
+func (s *Session) RegisterChannels() error {
+	channels := s.router.Channels()
+	if len(channels) == 0 {
+		return nil
+	}
+	return s.Send(RegisterChannel, []byte(strings.Join(channels, "\x00")))
+}
+
+func (s *Session) SendBrand(brand string) error {
+	buf := &bytes.Buffer{}
+	if err := codec.WriteString(buf, brand); err != nil {
+		return err
+	}
+	return s.Send(BrandChannel, buf.Bytes())
+}
+
+func splitChannels(data []byte) []string {
+	var channels []string
+	for _, name := range strings.Split(string(data), "\x00") {
+		if name != "" {
+			channels = append(channels, name)
+		}
+	}
+	return channels
+}
@@ -0,0 +1,59 @@
+package channels
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/NaymDev/mcgotocol/packet"
+)
+
+func register(s *Session, names ...string) error {
+	_, err := s.HandlePacket(&packet.ServerPluginMessage{
+		Channel: RegisterChannel,
+		Data:    []byte(strings.Join(names, "\x00")),
+	})
+	return err
+}
+
+func TestRegisterLimits(t *testing.T) {
+	s := NewSession(nil, NewRouter())
+
+	names := make([]string, MaxPeerChannels)
+	for i := range names {
+		names[i] = fmt.Sprintf("plugin:%d", i)
+	}
+	if err := register(s, names[:100]...); err != nil {
+		t.Fatal(err)
+	}
+	// already registered channels do not count twice
+	if err := register(s, names...); err != nil {
+		t.Fatal(err)
+	}
+	if err := register(s, "one:more"); !errors.Is(err, ErrTooManyChannels) {
+		t.Fatalf("129th channel: error = %v, want ErrTooManyChannels", err)
+	}
+	if s.PeerRegistered("one:more") || len(s.PeerChannels()) != MaxPeerChannels {
+		t.Fatal("rejected REGISTER changed the registered channels")
+	}
+
+	_, err := s.HandlePacket(&packet.ServerPluginMessage{Channel: UnregisterChannel, Data: []byte("plugin:0")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := register(s, "one:more"); err != nil {
+		t.Fatalf("REGISTER after UNREGISTER: %v", err)
+	}
+
+	s = NewSession(nil, NewRouter())
+	if err := register(s, "ok", strings.Repeat("x", MaxChannelLength+1)); !errors.Is(err, ErrChannelTooLong) {
+		t.Fatalf("long name: error = %v, want ErrChannelTooLong", err)
+	}
+	if s.PeerRegistered("ok") {
+		t.Fatal("REGISTER with a long name was applied partially")
+	}
+	if err := register(s, strings.Repeat("x", MaxChannelLength)); err != nil {
+		t.Fatalf("name of MaxChannelLength: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package chat
+
+// Builder builds components fluently:
+//
+//	chat.Text("Welcome ").Color(chat.Gold).Append(chat.Text(name).Bold(true)).Build()
+type Builder struct {
+	c Component
+}
+
+func Text(text string) *Builder {
+	return &Builder{c: Component{Text: text}}
+}
+
+func Translate(key string, with ...Component) *Builder {
+	return &Builder{c: Component{Translate: key, With: with}}
+}
+
+func ScoreOf(name, objective string) *Builder {
+	return &Builder{c: Component{Score: &Score{Name: name, Objective: objective}}}
+}
+
+func Selector(selector string) *Builder {
+	return &Builder{c: Component{Selector: selector}}
+}
+
+func (b *Builder) Color(color Color) *Builder {
+	b.c.Color = color
+	return b
+}
+
+func (b *Builder) Bold(v bool) *Builder {
+	b.c.Bold = &v
+	return b
+}
+
+func (b *Builder) Italic(v bool) *Builder {
+	b.c.Italic = &v
+	return b
+}
+
+func (b *Builder) Underlined(v bool) *Builder {
+	b.c.Underlined = &v
+	return b
+}
+
+func (b *Builder) Strikethrough(v bool) *Builder {
+	b.c.Strikethrough = &v
+	return b
+}
+
+func (b *Builder) Obfuscated(v bool) *Builder {
+	b.c.Obfuscated = &v
+	return b
+}
+
+func (b *Builder) Insertion(text string) *Builder {
+	b.c.Insertion = text
+	return b
+}
+
+func (b *Builder) Click(action ClickAction, value string) *Builder {
+	b.c.ClickEvent = &ClickEvent{Action: action, Value: value}
+	return b
+}
+
+func (b *Builder) Hover(action HoverAction, value Component) *Builder {
+	b.c.HoverEvent = &HoverEvent{Action: action, Value: value}
+	return b
+}
+
+// HoverText shows text when hovering.
+func (b *Builder) HoverText(text string) *Builder {
+	return b.Hover(ShowText, Component{Text: text})
+}
+
+// Append adds children. Builders and components are accepted.
+func (b *Builder) Append(children ...interface{ Build() Component }) *Builder {
+	for _, child := range children {
+		b.c.Extra = append(b.c.Extra, child.Build())
+	}
+	return b
+}
+
+func (b *Builder) Build() Component {
+	return b.c
+}
+
+// Build makes Component usable with Builder.Append.
+func (c Component) Build() Component {
+	return c
+}
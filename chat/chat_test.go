@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"reflect"
+	"testing"
+)
+
+// the join message of a 1.8 server
+const joinMessage = `{"color":"yellow","translate":"multiplayer.player.joined","with":[{"insertion":"Notch","clickEvent":{"action":"suggest_command","value":"/msg Notch "},"hoverEvent":{"action":"show_entity","value":{"text":"{name:\"Notch\",id:\"069a79f4-44e9-4726-a5be-fca90e38aaf5\"}"}},"text":"Notch"}]}`
+
+func TestParseVanilla(t *testing.T) {
+	c, err := Parse(joinMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Translate != "multiplayer.player.joined" || c.Color != Yellow || len(c.With) != 1 {
+		t.Fatalf("Parse = %+v", c)
+	}
+	name := c.With[0]
+	if name.Text != "Notch" || name.ClickEvent == nil || name.ClickEvent.Action != SuggestCommand ||
+		name.HoverEvent == nil || name.HoverEvent.Action != ShowEntity {
+		t.Fatalf("argument = %+v", name)
+	}
+	if got := c.PlainText(); got != "multiplayer.player.joined Notch" {
+		t.Errorf("PlainText = %q", got)
+	}
+	if got := c.Legacy(); got != "§emultiplayer.player.joined Notch" {
+		t.Errorf("Legacy = %q", got)
+	}
+
+	again, err := Parse(c.JSON())
+	if err != nil || !reflect.DeepEqual(again, c) {
+		t.Errorf("JSON round trip = %+v, %v", again, err)
+	}
+}
+
+func TestParseShorthand(t *testing.T) {
+	tests := []struct {
+		json string
+		want Component
+	}{
+		{`"hello"`, Component{Text: "hello"}},
+		{`["a", "b", {"text": "c"}]`, Component{Text: "a", Extra: []Component{{Text: "b"}, {Text: "c"}}}},
+		{`[]`, Component{}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.json)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%s) = %+v, %v, want %+v", tt.json, got, err, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		c    Component
+		want string
+	}{
+		{Component{}, `{"text":""}`},
+		{Component{Color: Red}, `{"text":"","color":"red"}`},
+		{Translate("chat.type.text").Build(), `{"translate":"chat.type.text"}`},
+		{
+			Text("Welcome ").Color(Gold).Append(Text("Notch").Bold(true)).Build(),
+			`{"text":"Welcome ","color":"gold","extra":[{"text":"Notch","bold":true}]}`,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.c.JSON(); got != tt.want {
+			t.Errorf("JSON = %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestLegacy(t *testing.T) {
+	tests := []struct {
+		legacy string
+		want   Component
+		back   string
+	}{
+		{"plain", Component{Text: "plain"}, "plain"},
+		{"§cRed", Component{Text: "Red", Color: Red}, "§cRed"},
+		{"§CRed", Component{Text: "Red", Color: Red}, "§cRed"},
+		{
+			"§6Welcome §lNotch",
+			Component{Extra: []Component{
+				{Text: "Welcome ", Color: Gold},
+				{Text: "Notch", Color: Gold, Bold: ptr(true)},
+			}},
+			"§6Welcome §6§lNotch",
+		},
+		{
+			// a color resets the formatting, §r everything
+			"§l§aA§rB",
+			Component{Extra: []Component{{Text: "A", Color: Green}, {Text: "B"}}},
+			"§aA§rB",
+		},
+		{"§zx§", Component{Text: "§zx§"}, "§zx§"},
+	}
+	for _, tt := range tests {
+		got := FromLegacy(tt.legacy)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FromLegacy(%q) = %+v, want %+v", tt.legacy, got, tt.want)
+		}
+		if back := got.Legacy(); back != tt.back {
+			t.Errorf("Legacy of %q = %q, want %q", tt.legacy, back, tt.back)
+		}
+	}
+}
+
+func TestStripLegacy(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                   "",
+		"plain":              "plain",
+		"§aA §lMinecraft§r!": "A Minecraft!",
+		"trailing§":          "trailing",
+		"§§x":                "x",
+	} {
+		if got := StripLegacy(in); got != want {
+			t.Errorf("StripLegacy(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
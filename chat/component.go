@@ -0,0 +1,187 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+type Color string
+
+const (
+	Black       Color = "black"
+	DarkBlue    Color = "dark_blue"
+	DarkGreen   Color = "dark_green"
+	DarkAqua    Color = "dark_aqua"
+	DarkRed     Color = "dark_red"
+	DarkPurple  Color = "dark_purple"
+	Gold        Color = "gold"
+	Gray        Color = "gray"
+	DarkGray    Color = "dark_gray"
+	Blue        Color = "blue"
+	Green       Color = "green"
+	Aqua        Color = "aqua"
+	Red         Color = "red"
+	LightPurple Color = "light_purple"
+	Yellow      Color = "yellow"
+	White       Color = "white"
+	Reset       Color = "reset"
+)
+
+type ClickAction string
+
+const (
+	OpenURL        ClickAction = "open_url"
+	RunCommand     ClickAction = "run_command"
+	SuggestCommand ClickAction = "suggest_command"
+	ChangePage     ClickAction = "change_page"
+)
+
+type HoverAction string
+
+const (
+	ShowText        HoverAction = "show_text"
+	ShowAchievement HoverAction = "show_achievement"
+	ShowItem        HoverAction = "show_item"
+	ShowEntity      HoverAction = "show_entity"
+)
+
+type ClickEvent struct {
+	Action ClickAction `json:"action"`
+	Value  string      `json:"value"`
+}
+
+// HoverEvent carries a component for show_text and the (SNBT) item, entity
+// or achievement description as text for the other actions.
+type HoverEvent struct {
+	Action HoverAction `json:"action"`
+	Value  Component   `json:"value"`
+}
+
+type Score struct {
+	Name      string `json:"name"`
+	Objective string `json:"objective"`
+	Value     string `json:"value,omitempty"`
+}
+
+// Component is a JSON chat component. Exactly one of Text, Translate, Score
+// and Selector is the content; a component without any is an empty text
+// component. Nil style fields are inherited from the parent.
+type Component struct {
+	Text      string      `json:"text,omitempty"`
+	Translate string      `json:"translate,omitempty"`
+	With      []Component `json:"with,omitempty"`
+	Score     *Score      `json:"score,omitempty"`
+	Selector  string      `json:"selector,omitempty"`
+
+	Color         Color `json:"color,omitempty"`
+	Bold          *bool `json:"bold,omitempty"`
+	Italic        *bool `json:"italic,omitempty"`
+	Underlined    *bool `json:"underlined,omitempty"`
+	Strikethrough *bool `json:"strikethrough,omitempty"`
+	Obfuscated    *bool `json:"obfuscated,omitempty"`
+
+	Insertion  string      `json:"insertion,omitempty"`
+	ClickEvent *ClickEvent `json:"clickEvent,omitempty"`
+	HoverEvent *HoverEvent `json:"hoverEvent,omitempty"`
+
+	Extra []Component `json:"extra,omitempty"`
+}
+
+// component has the same fields without the custom (un)marshalers.
+type component Component
+
+func (c Component) isText() bool {
+	return c.Translate == "" && c.Score == nil && c.Selector == ""
+}
+
+// MarshalJSON always emits "text" for text components, even if it is empty,
+// since the client needs one content field.
+func (c Component) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(component(c))
+	if err != nil || !c.isText() || c.Text != "" {
+		return data, err
+	}
+	if len(data) == 2 {
+		return []byte(`{"text":""}`), nil
+	}
+	return append([]byte(`{"text":"",`), data[1:]...), nil
+}
+
+// UnmarshalJSON accepts the shorthand forms used by vanilla as well: a plain
+// string is a text component and an array is its first element with the
+// others appended as extra.
+func (c *Component) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) > 0 && data[0] == '"':
+		*c = Component{}
+		return json.Unmarshal(data, &c.Text)
+	case len(data) > 0 && data[0] == '[':
+		var parts []Component
+		if err := json.Unmarshal(data, &parts); err != nil {
+			return err
+		}
+		*c = Component{}
+		if len(parts) > 0 {
+			*c = parts[0]
+			c.Extra = append(c.Extra, parts[1:]...)
+		}
+		return nil
+	}
+	var raw component
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Component(raw)
+	return nil
+}
+
+// Parse decodes a JSON chat component.
+func Parse(s string) (Component, error) {
+	var c Component
+	err := json.Unmarshal([]byte(s), &c)
+	return c, err
+}
+
+// JSON encodes c as the wire representation.
+func (c Component) JSON() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		// only reachable with invalid UTF-8 replaced by encoding/json anyway
+		return `{"text":""}`
+	}
+	return string(data)
+}
+
+// PlainText flattens c and its children into unformatted text, e.g. for
+// logs. Translations are not resolved: the key is followed by its arguments.
+func (c Component) PlainText() string {
+	var sb strings.Builder
+	c.writePlain(&sb)
+	return sb.String()
+}
+
+func (c Component) writePlain(sb *strings.Builder) {
+	switch {
+	case c.Translate != "":
+		sb.WriteString(c.Translate)
+		for i, arg := range c.With {
+			if i == 0 {
+				sb.WriteString(" ")
+			} else {
+				sb.WriteString(", ")
+			}
+			arg.writePlain(sb)
+		}
+	case c.Score != nil:
+		sb.WriteString(c.Score.Value)
+	case c.Selector != "":
+		sb.WriteString(c.Selector)
+	default:
+		sb.WriteString(c.Text)
+	}
+	for _, extra := range c.Extra {
+		extra.writePlain(sb)
+	}
+}
@@ -0,0 +1,184 @@
+package chat
+
+import "strings"
+
+// LegacyPrefix starts a legacy formatting code.
+const LegacyPrefix = '§'
+
+var legacyColors = map[rune]Color{
+	'0': Black, '1': DarkBlue, '2': DarkGreen, '3': DarkAqua,
+	'4': DarkRed, '5': DarkPurple, '6': Gold, '7': Gray,
+	'8': DarkGray, '9': Blue, 'a': Green, 'b': Aqua,
+	'c': Red, 'd': LightPurple, 'e': Yellow, 'f': White,
+}
+
+var colorCodes = func() map[Color]rune {
+	codes := make(map[Color]rune, len(legacyColors))
+	for code, color := range legacyColors {
+		codes[color] = code
+	}
+	return codes
+}()
+
+type legacyStyle struct {
+	color                                               Color
+	bold, italic, underlined, strikethrough, obfuscated bool
+}
+
+func (s legacyStyle) apply(c *Component) {
+	c.Color = s.color
+	setFlag := func(dst **bool, v bool) {
+		if v {
+			t := true
+			*dst = &t
+		}
+	}
+	setFlag(&c.Bold, s.bold)
+	setFlag(&c.Italic, s.italic)
+	setFlag(&c.Underlined, s.underlined)
+	setFlag(&c.Strikethrough, s.strikethrough)
+	setFlag(&c.Obfuscated, s.obfuscated)
+}
+
+// FromLegacy converts text with § formatting codes into a component. Like
+// in vanilla a color code resets all formatting.
+func FromLegacy(s string) Component {
+	root := Component{}
+	var style legacyStyle
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		part := Component{Text: text.String()}
+		style.apply(&part)
+		root.Extra = append(root.Extra, part)
+		text.Reset()
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != LegacyPrefix || i+1 >= len(runes) {
+			text.WriteRune(runes[i])
+			continue
+		}
+		code := runes[i+1]
+		if code >= 'A' && code <= 'Z' {
+			code += 'a' - 'A'
+		}
+		i++
+		flush()
+		if color, ok := legacyColors[code]; ok {
+			style = legacyStyle{color: color}
+			continue
+		}
+		switch code {
+		case 'k':
+			style.obfuscated = true
+		case 'l':
+			style.bold = true
+		case 'm':
+			style.strikethrough = true
+		case 'n':
+			style.underlined = true
+		case 'o':
+			style.italic = true
+		case 'r':
+			style = legacyStyle{}
+		default:
+			// unknown codes are kept as text
+			text.WriteRune(LegacyPrefix)
+			text.WriteRune(code)
+		}
+	}
+	flush()
+
+	if len(root.Extra) == 1 {
+		return root.Extra[0]
+	}
+	return root
+}
+
+// StripLegacy removes the § formatting codes from s, including a trailing §
+// without code.
+func StripLegacy(s string) string {
+	if !strings.ContainsRune(s, LegacyPrefix) {
+		return s
+	}
+	var sb strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == LegacyPrefix {
+			i++
+			continue
+		}
+		sb.WriteRune(runes[i])
+	}
+	return sb.String()
+}
+
+// Legacy converts c into text with § formatting codes. Click and hover
+// events cannot be represented and are dropped; translations are flattened
+// like in PlainText.
+func (c Component) Legacy() string {
+	var sb strings.Builder
+	var last legacyStyle
+	c.writeLegacy(&sb, legacyStyle{}, &last)
+	return sb.String()
+}
+
+// writeLegacy only emits codes when the style changes compared to last.
+func (c Component) writeLegacy(sb *strings.Builder, parent legacyStyle, last *legacyStyle) {
+	style := parent
+	if c.Color != "" {
+		style.color = c.Color
+	}
+	inherit := func(v *bool, p bool) bool {
+		if v != nil {
+			return *v
+		}
+		return p
+	}
+	style.bold = inherit(c.Bold, parent.bold)
+	style.italic = inherit(c.Italic, parent.italic)
+	style.underlined = inherit(c.Underlined, parent.underlined)
+	style.strikethrough = inherit(c.Strikethrough, parent.strikethrough)
+	style.obfuscated = inherit(c.Obfuscated, parent.obfuscated)
+
+	content := Component{Text: c.Text, Translate: c.Translate, With: c.With, Score: c.Score, Selector: c.Selector}.PlainText()
+	if content != "" {
+		if style != *last {
+			writeStyle(sb, style)
+			*last = style
+		}
+		sb.WriteString(content)
+	}
+	for _, extra := range c.Extra {
+		extra.writeLegacy(sb, style, last)
+	}
+}
+
+func writeStyle(sb *strings.Builder, s legacyStyle) {
+	if code, ok := colorCodes[s.color]; ok {
+		sb.WriteRune(LegacyPrefix)
+		sb.WriteRune(code)
+	} else {
+		sb.WriteString("§r")
+	}
+	for _, f := range []struct {
+		set  bool
+		code rune
+	}{
+		{s.obfuscated, 'k'},
+		{s.bold, 'l'},
+		{s.strikethrough, 'm'},
+		{s.underlined, 'n'},
+		{s.italic, 'o'},
+	} {
+		if f.set {
+			sb.WriteRune(LegacyPrefix)
+			sb.WriteRune(f.code)
+		}
+	}
+}
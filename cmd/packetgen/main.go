@@ -0,0 +1,554 @@
+// Command packetgen generates packet types and their registry wiring from
+// minecraft-data's protocol.json.
+//
+// Only the packets named in the packet list are generated. The list maps the
+// minecraft-data name of each packet to its Go name (prefixed with Server or
+// Client) and may rename fields, override their type or embed them:
+//
+//	{"play": {"toClient": {"named_entity_spawn": {
+//		"name": "SpawnPlayer",
+//		"fields": {"yaw": {"type": "angle"}, "pitch": {"type": "angle"}}
+//	}}}}
+//
+// Fields of nested containers are addressed as "meta.x". Listed packets get
+// a struct, ID, Encode and Decode. Packets marked "manual" are only
+// registered and have to be written by hand in the packet package. A listed
+// packet using a type the generator does not support is an error.
+//
+//	go run ./cmd/packetgen -protocol data/pc/1.8/protocol.json \
+//		-list packet/packets.json -packets packet/packets_gen.go \
+//		-registry state/register_gen.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const modulePath = "github.com/NaymDev/mcgotocol"
+
+// protocolState maps the minecraft-data state keys to our registries.
+var protocolStates = []struct {
+	key      string
+	registry string
+	comment  string
+}{
+	{"handshaking", "Handshake", "HANDSHAKE"},
+	{"status", "Status", "STATUS"},
+	{"login", "Login", "LOGIN"},
+	{"play", "Play", "PLAY"},
+}
+
+var directions = []struct {
+	key      string
+	prefix   string
+	receiver string
+	bound    string
+}{
+	{"toServer", "Server", "s", "ServerBound"},
+	{"toClient", "Client", "c", "ClientBound"},
+}
+
+// nativeTypes maps the minecraft-data type names that have a codec
+// counterpart. chat and angle do not exist in 1.8's protocol.json, which
+// uses string and i8, but can be set as type overrides in the packet list.
+var nativeTypes = map[string]goField{
+	"varint":         {Type: "codec.VarInt"},
+	"varlong":        {Type: "codec.VarLong"},
+	"bool":           {Type: "bool"},
+	"i8":             {Type: "int8"},
+	"u8":             {Type: "uint8"},
+	"i16":            {Type: "int16"},
+	"u16":            {Type: "uint16"},
+	"i32":            {Type: "int32"},
+	"i64":            {Type: "int64"},
+	"f32":            {Type: "float32"},
+	"f64":            {Type: "float64"},
+	"string":         {Type: "string"},
+	"chat":           {Type: "codec.Chat"},
+	"angle":          {Type: "codec.Angle"},
+	"UUID":           {Type: "uuid.UUID"},
+	"position":       {Type: "codec.Position"},
+	"slot":           {Type: "codec.ItemSlot"},
+	"nbt":            {Type: "nbt.Compound"},
+	"entityMetadata": {Type: "[]codec.EntityMetadata"},
+	"restBuffer":     {Type: "[]byte", Tag: "rest"},
+}
+
+var initialisms = map[string]string{
+	"id":   "ID",
+	"uuid": "UUID",
+	"json": "JSON",
+	"url":  "URL",
+	"ip":   "IP",
+}
+
+var errUnsupported = errors.New("unsupported type")
+
+// packetList is keyed by state, direction and minecraft-data packet name.
+type packetList map[string]map[string]map[string]packetSpec
+
+type packetSpec struct {
+	Name   string                   `json:"name"`
+	Manual bool                     `json:"manual"`
+	Fields map[string]fieldOverride `json:"fields"`
+}
+
+type fieldOverride struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Embed bool   `json:"embed"`
+}
+
+type rawField struct {
+	Name string          `json:"name"`
+	Anon bool            `json:"anon"`
+	Type json.RawMessage `json:"type"`
+}
+
+type goField struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+type goStruct struct {
+	Name   string
+	Fields []goField
+}
+
+type packetDef struct {
+	Name     string
+	ID       int32
+	Receiver string
+	Bound    string
+	Structs  []goStruct // the packet first, then nested containers
+	Manual   bool       // only registered, the type is hand-written
+}
+
+type generator struct {
+	imports map[string]bool
+}
+
+func main() {
+	protocolPath := flag.String("protocol", "data/pc/1.8/protocol.json", "minecraft-data protocol.json")
+	listPath := flag.String("list", "packet/packets.json", "packets to generate")
+	packetsPath := flag.String("packets", "packet/packets_gen.go", "output file for packet types")
+	registryPath := flag.String("registry", "state/register_gen.go", "output file for InitRegistries")
+	flag.Parse()
+
+	data, err := os.ReadFile(*protocolPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var protocol map[string]json.RawMessage
+	if err := json.Unmarshal(data, &protocol); err != nil {
+		log.Fatalf("%s: %v", *protocolPath, err)
+	}
+	data, err = os.ReadFile(*listPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var list packetList
+	if err := json.Unmarshal(data, &list); err != nil {
+		log.Fatalf("%s: %v", *listPath, err)
+	}
+
+	g := &generator{imports: map[string]bool{}}
+	byState := make([][]packetDef, len(protocolStates))
+	for i, st := range protocolStates {
+		raw, ok := protocol[st.key]
+		if !ok {
+			if len(list[st.key]) > 0 {
+				log.Fatalf("%s: state not in %s", st.key, *protocolPath)
+			}
+			continue
+		}
+		var state map[string]struct {
+			Types map[string]json.RawMessage `json:"types"`
+		}
+		if err := json.Unmarshal(raw, &state); err != nil {
+			log.Fatalf("%s: %v", st.key, err)
+		}
+		for _, dir := range directions {
+			packets, err := g.packets(state[dir.key].Types, list[st.key][dir.key], dir.prefix, dir.receiver, dir.bound)
+			if err != nil {
+				log.Fatalf("%s.%s: %v", st.key, dir.key, err)
+			}
+			byState[i] = append(byState[i], packets...)
+		}
+	}
+
+	// name the source relative to the module root in the generated headers
+	source := filepath.ToSlash(filepath.Clean(*protocolPath))
+	for strings.HasPrefix(source, "../") {
+		source = source[len("../"):]
+	}
+	if err := write(*packetsPath, g.packetFile(source, byState)); err != nil {
+		log.Fatal(err)
+	}
+	if err := write(*registryPath, registryFile(source, byState)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func write(path string, src []byte) error {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w\n%s", path, err, src)
+	}
+	return os.WriteFile(path, formatted, 0o644)
+}
+
+// packets reads the "packet" mapper of one direction and resolves the
+// packet_<name> container of every listed packet.
+func (g *generator) packets(types map[string]json.RawMessage, specs map[string]packetSpec, prefix, receiver, bound string) ([]packetDef, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	raw, ok := types["packet"]
+	if !ok {
+		return nil, fmt.Errorf("packet mapper is not defined")
+	}
+	var fields []rawField
+	if err := unmarshalContainer(raw, &fields); err != nil {
+		return nil, fmt.Errorf("packet: %w", err)
+	}
+	ids := map[string]int32{}
+	for _, f := range fields {
+		if f.Name != "name" {
+			continue
+		}
+		var mapper []json.RawMessage
+		if err := json.Unmarshal(f.Type, &mapper); err != nil || len(mapper) != 2 {
+			return nil, fmt.Errorf("packet: name is not a mapper")
+		}
+		var opts struct {
+			Mappings map[string]string `json:"mappings"`
+		}
+		if err := json.Unmarshal(mapper[1], &opts); err != nil {
+			return nil, fmt.Errorf("packet: %w", err)
+		}
+		for hex, name := range opts.Mappings {
+			id, err := strconv.ParseInt(hex, 0, 32)
+			if err != nil {
+				return nil, fmt.Errorf("packet id %q: %w", hex, err)
+			}
+			ids[name] = int32(id)
+		}
+	}
+
+	var result []packetDef
+	for name, spec := range specs {
+		id, ok := ids[name]
+		if !ok {
+			return nil, fmt.Errorf("%s has no packet id", name)
+		}
+		if spec.Name == "" {
+			return nil, fmt.Errorf("%s has no Go name", name)
+		}
+		def := packetDef{
+			Name:     prefix + spec.Name,
+			ID:       id,
+			Receiver: receiver,
+			Bound:    bound,
+			Manual:   spec.Manual,
+		}
+		result = append(result, def)
+		if spec.Manual {
+			continue
+		}
+		body, ok := types["packet_"+name]
+		if !ok {
+			return nil, fmt.Errorf("packet_%s is not defined", name)
+		}
+		structs, err := g.container(def.Name, "", spec.Fields, body)
+		if err != nil {
+			if errors.Is(err, errUnsupported) {
+				err = fmt.Errorf("%w; write it by hand and mark it manual", err)
+			}
+			return nil, fmt.Errorf("packet_%s: %w", name, err)
+		}
+		result[len(result)-1].Structs = structs
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func unmarshalContainer(raw json.RawMessage, fields *[]rawField) error {
+	var container []json.RawMessage
+	if err := json.Unmarshal(raw, &container); err != nil || len(container) != 2 {
+		return fmt.Errorf("not a container")
+	}
+	var kind string
+	if err := json.Unmarshal(container[0], &kind); err != nil || kind != "container" {
+		return fmt.Errorf("not a container")
+	}
+	return json.Unmarshal(container[1], fields)
+}
+
+// container converts a container into a struct named name, followed by the
+// structs of any nested containers. path is the prefix of its fields in
+// overrides.
+func (g *generator) container(name, path string, overrides map[string]fieldOverride, raw json.RawMessage) ([]goStruct, error) {
+	var fields []rawField
+	if err := unmarshalContainer(raw, &fields); err != nil {
+		return nil, err
+	}
+	structs := []goStruct{{Name: name}}
+	for _, f := range fields {
+		if f.Anon {
+			return nil, fmt.Errorf("%w: anonymous field", errUnsupported)
+		}
+		if f.Name == "" {
+			return nil, fmt.Errorf("field without name")
+		}
+		override := overrides[path+f.Name]
+		fieldName := goName(f.Name)
+		if override.Name != "" {
+			fieldName = goName(override.Name)
+		}
+		typ := f.Type
+		if override.Type != "" {
+			typ, _ = json.Marshal(override.Type)
+		}
+
+		var kind string
+		var args []json.RawMessage
+		if err := json.Unmarshal(typ, &args); err == nil && len(args) == 2 {
+			_ = json.Unmarshal(args[0], &kind)
+		}
+		if kind == "option" {
+			field, nested, err := g.fieldType(name+fieldName, path+f.Name+".", overrides, args[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			guard := "Has" + fieldName
+			tag := "optional=" + guard
+			if field.Tag != "" {
+				tag = field.Tag + "," + tag
+			}
+			structs[0].Fields = append(structs[0].Fields,
+				goField{Name: guard, Type: "bool"},
+				goField{Name: fieldName, Type: "*" + field.Type, Tag: tag})
+			structs = append(structs, nested...)
+			continue
+		}
+
+		field, nested, err := g.fieldType(name+fieldName, path+f.Name+".", overrides, typ)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		field.Name = fieldName
+		if override.Embed {
+			if field.Tag != "" || strings.HasPrefix(field.Type, "[]") {
+				return nil, fmt.Errorf("%s: cannot embed %s", f.Name, field.Type)
+			}
+			field.Name = ""
+		}
+		structs[0].Fields = append(structs[0].Fields, field)
+		structs = append(structs, nested...)
+	}
+	return structs, nil
+}
+
+// fieldType resolves a protocol type to a Go type and mc tag. Nested
+// containers become structs named name.
+func (g *generator) fieldType(name, path string, overrides map[string]fieldOverride, raw json.RawMessage) (goField, []goStruct, error) {
+	var simple string
+	if err := json.Unmarshal(raw, &simple); err == nil {
+		field, ok := nativeTypes[simple]
+		if !ok {
+			return goField{}, nil, fmt.Errorf("%w %q", errUnsupported, simple)
+		}
+		g.use(field.Type)
+		return field, nil, nil
+	}
+
+	var args []json.RawMessage
+	if err := json.Unmarshal(raw, &args); err != nil || len(args) != 2 {
+		return goField{}, nil, fmt.Errorf("malformed type %s", raw)
+	}
+	var kind string
+	if err := json.Unmarshal(args[0], &kind); err != nil {
+		return goField{}, nil, fmt.Errorf("malformed type %s", raw)
+	}
+	var opts struct {
+		CountType string          `json:"countType"`
+		Type      json.RawMessage `json:"type"`
+	}
+
+	switch kind {
+	case "pstring", "buffer", "array":
+		if err := json.Unmarshal(args[1], &opts); err != nil {
+			return goField{}, nil, err
+		}
+		// EncodeStruct only writes VarInt counts. 1.8 also prefixes some
+		// arrays with a byte or short, or takes the count from another field.
+		switch opts.CountType {
+		case "varint":
+		case "":
+			return goField{}, nil, fmt.Errorf("%w: %s with a count from another field", errUnsupported, kind)
+		default:
+			return goField{}, nil, fmt.Errorf("%w: %s with %q count", errUnsupported, kind, opts.CountType)
+		}
+		switch kind {
+		case "pstring":
+			return goField{Type: "string"}, nil, nil
+		case "buffer":
+			return goField{Type: "[]byte"}, nil, nil
+		}
+		elem, nested, err := g.fieldType(name, path, overrides, opts.Type)
+		if err != nil {
+			return goField{}, nil, err
+		}
+		if strings.HasPrefix(elem.Type, "[]") && elem.Type != "[]codec.EntityMetadata" {
+			return goField{}, nil, fmt.Errorf("%w: nested array", errUnsupported)
+		}
+		field := goField{Type: "[]" + elem.Type}
+		if elem.Tag != "" {
+			if elem.Tag == "rest" {
+				return goField{}, nil, fmt.Errorf("%w: array of restBuffer", errUnsupported)
+			}
+			field.Tag = "array,elem=" + elem.Tag
+		}
+		return field, nested, nil
+	case "container":
+		structs, err := g.container(name, path, overrides, raw)
+		if err != nil {
+			return goField{}, nil, err
+		}
+		return goField{Type: name}, structs, nil
+	}
+	return goField{}, nil, fmt.Errorf("%w %q", errUnsupported, kind)
+}
+
+func (g *generator) use(typ string) {
+	switch {
+	case strings.Contains(typ, "uuid."):
+		g.imports["github.com/google/uuid"] = true
+	case strings.Contains(typ, "nbt."):
+		g.imports[modulePath+"/nbt"] = true
+	}
+}
+
+func (g *generator) packetFile(source string, byState [][]packetDef) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by packetgen from %s. DO NOT EDIT.\n\n", source)
+	buf.WriteString("package packet\n\nimport (\n")
+	imports := []string{modulePath + "/codec", modulePath + "/proto", "io"}
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&buf, "\t%q\n", imp)
+	}
+	buf.WriteString(")\n")
+
+	for _, packets := range byState {
+		for _, p := range packets {
+			if p.Manual {
+				continue
+			}
+			for i, s := range p.Structs {
+				if len(s.Fields) == 0 {
+					fmt.Fprintf(&buf, "\ntype %s struct{}\n", s.Name)
+				} else {
+					fmt.Fprintf(&buf, "\ntype %s struct {\n", s.Name)
+				}
+				for _, f := range s.Fields {
+					fmt.Fprintf(&buf, "\t%s %s", f.Name, f.Type)
+					if f.Tag != "" {
+						fmt.Fprintf(&buf, " `mc:%q`", f.Tag)
+					}
+					buf.WriteString("\n")
+				}
+				if len(s.Fields) > 0 {
+					buf.WriteString("}\n")
+				}
+				if i > 0 {
+					continue
+				}
+				fmt.Fprintf(&buf, "\nvar _ proto.Packet = (*%s)(nil)\n", p.Name)
+				fmt.Fprintf(&buf, "\nfunc (%s *%s) ID() int32 {\n\treturn 0x%02X\n}\n", p.Receiver, p.Name, p.ID)
+				fmt.Fprintf(&buf, "\nfunc (%s *%s) Encode(writer io.Writer) error {\n\treturn codec.EncodeStruct(writer, %s)\n}\n", p.Receiver, p.Name, p.Receiver)
+				fmt.Fprintf(&buf, "\nfunc (%s *%s) Decode(reader io.Reader) error {\n\treturn codec.DecodeStruct(reader, %s)\n}\n", p.Receiver, p.Name, p.Receiver)
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+func registryFile(source string, byState [][]packetDef) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by packetgen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&buf, "package state\n\nimport %q\n\n", modulePath+"/packet")
+	buf.WriteString("func InitRegistries() {\n")
+	for i, packets := range byState {
+		if len(packets) == 0 {
+			continue
+		}
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "\t// %s\n", protocolStates[i].comment)
+		for j, p := range packets {
+			if j > 0 && p.Bound != packets[j-1].Bound {
+				buf.WriteString("\n")
+			}
+			fmt.Fprintf(&buf, "\t%s.%s.Register(&packet.%s{})\n", protocolStates[i].registry, p.Bound, p.Name)
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// goName converts snake_case and camelCase names to exported Go names,
+// keeping initialisms such as ID and UUID upper case.
+func goName(name string) string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if r == '_' {
+			words = append(words, string(word))
+			word = word[:0]
+			continue
+		}
+		boundary := unicode.IsUpper(r) && len(word) > 0 &&
+			(unicode.IsLower(word[len(word)-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])))
+		if boundary {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+		word = append(word, r)
+	}
+	words = append(words, string(word))
+
+	var b strings.Builder
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if upper, ok := initialisms[strings.ToLower(w)]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		rs := []rune(w)
+		b.WriteRune(unicode.ToUpper(rs[0]))
+		b.WriteString(string(rs[1:]))
+	}
+	return b.String()
+}
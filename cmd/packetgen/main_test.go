@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"keepAliveId":        "KeepAliveID",
+		"playerUUID":         "PlayerUUID",
+		"named_entity_spawn": "NamedEntitySpawn",
+		"UUID":               "UUID",
+		"serverId":           "ServerID",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestContainerOverrides(t *testing.T) {
+	raw := json.RawMessage(`["container", [
+		{"name": "location", "type": "position"},
+		{"name": "yaw", "type": "i8"},
+		{"name": "meta", "type": ["array", {"countType": "varint", "type": ["container", [
+			{"name": "x", "type": "i32"}
+		]]}]}
+	]]`)
+	overrides := map[string]fieldOverride{
+		"location": {Embed: true},
+		"yaw":      {Type: "angle"},
+		"meta.x":   {Name: "chunkX"},
+	}
+	g := &generator{imports: map[string]bool{}}
+	structs, err := g.container("ClientTest", "", overrides, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []goStruct{
+		{Name: "ClientTest", Fields: []goField{
+			{Type: "codec.Position"},
+			{Name: "Yaw", Type: "codec.Angle"},
+			{Name: "Meta", Type: "[]ClientTestMeta"},
+		}},
+		{Name: "ClientTestMeta", Fields: []goField{{Name: "ChunkX", Type: "int32"}}},
+	}
+	if !reflect.DeepEqual(structs, want) {
+		t.Fatalf("container = %+v, want %+v", structs, want)
+	}
+}
+
+func TestContainerUnsupported(t *testing.T) {
+	for _, raw := range []string{
+		`["container", [{"anon": true, "type": "position"}]]`,
+		`["container", [{"name": "a", "type": ["switch", {"compareTo": "b", "fields": {}}]}]]`,
+		`["container", [{"name": "a", "type": "topBitSetTerminatedArray"}]]`,
+		`["container", [{"name": "a", "type": ["array", {"countType": "i16", "type": "i8"}]}]]`,
+		`["container", [{"name": "a", "type": ["array", {"countType": "i8", "type": "i32"}]}]]`,
+		`["container", [{"name": "a", "type": ["buffer", {"countType": "i16"}]}]]`,
+		`["container", [{"name": "n", "type": "i8"}, {"name": "a", "type": ["array", {"count": "n", "type": "i32"}]}]]`,
+	} {
+		g := &generator{imports: map[string]bool{}}
+		if _, err := g.container("ClientTest", "", nil, json.RawMessage(raw)); !errors.Is(err, errUnsupported) {
+			t.Errorf("%s: error = %v, want errUnsupported", raw, err)
+		}
+	}
+}
@@ -2,29 +2,62 @@ package codec
 
 import (
 	"errors"
+	"github.com/NaymDev/mcgotocol/chat"
 	"io"
+	"unicode/utf16"
 )
 
+// MaxChatLength is the limit for chat JSON in UTF-16 code units, which is how
+// Java counts the length of a string.
 const MaxChatLength = 32767
 
 var ErrStringTooLong = errors.New("string exceeds maximum length")
 
+// Chat is the JSON encoded form of a chat component as it is sent on the
+// wire. Use NewChat and Component to convert from and to chat.Component.
 type Chat string
 
+func NewChat(c chat.Component) Chat {
+	return Chat(c.JSON())
+}
+
+func (c Chat) Component() (chat.Component, error) {
+	return chat.Parse(string(c))
+}
+
 func ReadChat(r io.Reader) (Chat, error) {
 	s, err := ReadString(r)
 	if err != nil {
 		return "", err
 	}
-	if len(s) > MaxChatLength {
+	if chatTooLong(s) {
 		return "", ErrStringTooLong
 	}
 	return Chat(s), nil
 }
 
 func WriteChat(w io.Writer, c Chat) error {
-	if len(c) > MaxChatLength {
+	if chatTooLong(string(c)) {
 		return ErrStringTooLong
 	}
 	return WriteString(w, string(c))
 }
+
+func AppendChat(dst []byte, c Chat) ([]byte, error) {
+	if chatTooLong(string(c)) {
+		return dst, ErrStringTooLong
+	}
+	return AppendString(dst, string(c)), nil
+}
+
+func chatTooLong(s string) bool {
+	// a string never has more UTF-16 code units than UTF-8 bytes
+	if len(s) <= MaxChatLength {
+		return false
+	}
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n > MaxChatLength
+}
@@ -0,0 +1,249 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ============================
+//  1.8 chunk columns
+// ============================
+//
+// The data of a Chunk Data packet holds, for the sections set in the primary
+// bit mask and in this order: the block states of every section (4096
+// little-endian uint16 of id<<4|meta each), then the block light of every
+// section, then the sky light of every section (only in dimensions with a
+// sky), then the 256 biome bytes if the column is sent ground-up.
+
+const (
+	SectionsPerChunk = 16
+	SectionVolume    = 16 * 16 * 16
+	NibbleArraySize  = SectionVolume / 2
+	BiomesPerChunk   = 16 * 16
+)
+
+var ErrChunkDataSize = errors.New("chunk data size does not match bit mask")
+
+// BlockState packs a block id and its metadata.
+func BlockState(id uint16, meta uint8) uint16 {
+	return id<<4 | uint16(meta&0xF)
+}
+
+// ChunkSection is a 16x16x16 part of a chunk column. Blocks are indexed
+// y<<8 | z<<4 | x.
+type ChunkSection struct {
+	Blocks     [SectionVolume]uint16
+	BlockLight [NibbleArraySize]byte
+	SkyLight   [NibbleArraySize]byte
+}
+
+// NewChunkSection returns an empty section with full sky light.
+func NewChunkSection() *ChunkSection {
+	s := &ChunkSection{}
+	for i := range s.SkyLight {
+		s.SkyLight[i] = 0xFF
+	}
+	return s
+}
+
+func sectionIndex(x, y, z int) int {
+	return (y&0xF)<<8 | (z&0xF)<<4 | x&0xF
+}
+
+func getNibble(a *[NibbleArraySize]byte, i int) uint8 {
+	if i&1 == 0 {
+		return a[i>>1] & 0xF
+	}
+	return a[i>>1] >> 4
+}
+
+func setNibble(a *[NibbleArraySize]byte, i int, v uint8) {
+	if i&1 == 0 {
+		a[i>>1] = a[i>>1]&0xF0 | v&0xF
+	} else {
+		a[i>>1] = a[i>>1]&0x0F | v<<4
+	}
+}
+
+func (s *ChunkSection) Block(x, y, z int) uint16 {
+	return s.Blocks[sectionIndex(x, y, z)]
+}
+
+func (s *ChunkSection) SetBlock(x, y, z int, state uint16) {
+	s.Blocks[sectionIndex(x, y, z)] = state
+}
+
+func (s *ChunkSection) BlockLightAt(x, y, z int) uint8 {
+	return getNibble(&s.BlockLight, sectionIndex(x, y, z))
+}
+
+func (s *ChunkSection) SetBlockLight(x, y, z int, light uint8) {
+	setNibble(&s.BlockLight, sectionIndex(x, y, z), light)
+}
+
+func (s *ChunkSection) SkyLightAt(x, y, z int) uint8 {
+	return getNibble(&s.SkyLight, sectionIndex(x, y, z))
+}
+
+func (s *ChunkSection) SetSkyLight(x, y, z int, light uint8) {
+	setNibble(&s.SkyLight, sectionIndex(x, y, z), light)
+}
+
+// IsEmpty reports whether the section only contains air.
+func (s *ChunkSection) IsEmpty() bool {
+	for _, b := range s.Blocks {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ChunkColumn is a 16x256x16 column of sections. Nil sections are empty.
+type ChunkColumn struct {
+	X        int32
+	Z        int32
+	Sections [SectionsPerChunk]*ChunkSection
+	Biomes   [BiomesPerChunk]byte // indexed z<<4 | x
+}
+
+func (c *ChunkColumn) Block(x, y, z int) uint16 {
+	s := c.Sections[(y>>4)&0xF]
+	if s == nil {
+		return 0
+	}
+	return s.Block(x, y, z)
+}
+
+// SetBlock sets the block at y 0-255, creating the section if needed.
+func (c *ChunkColumn) SetBlock(x, y, z int, state uint16) {
+	i := (y >> 4) & 0xF
+	if c.Sections[i] == nil {
+		if state == 0 {
+			return
+		}
+		c.Sections[i] = NewChunkSection()
+	}
+	c.Sections[i].SetBlock(x, y, z, state)
+}
+
+func (c *ChunkColumn) Biome(x, z int) byte {
+	return c.Biomes[(z&0xF)<<4|x&0xF]
+}
+
+func (c *ChunkColumn) SetBiome(x, z int, biome byte) {
+	c.Biomes[(z&0xF)<<4|x&0xF] = biome
+}
+
+// PrimaryBitMask has a bit set for every section that is not empty.
+func (c *ChunkColumn) PrimaryBitMask() uint16 {
+	var mask uint16
+	for i, s := range c.Sections {
+		if s != nil && !s.IsEmpty() {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+// ChunkDataSize returns the length of the data of a column with the given
+// bit mask.
+func ChunkDataSize(mask uint16, skyLight, groundUp bool) int {
+	sections := 0
+	for m := mask; m != 0; m &= m - 1 {
+		sections++
+	}
+	perSection := SectionVolume*2 + NibbleArraySize
+	if skyLight {
+		perSection += NibbleArraySize
+	}
+	size := sections * perSection
+	if groundUp {
+		size += BiomesPerChunk
+	}
+	return size
+}
+
+// AppendData appends the sections in mask (and the biomes if groundUp) to
+// dst in the Chunk Data format. Sections in mask that are nil are written
+// as empty sections.
+func (c *ChunkColumn) AppendData(dst []byte, mask uint16, skyLight, groundUp bool) []byte {
+	empty := NewChunkSection()
+	section := func(i int) *ChunkSection {
+		if c.Sections[i] == nil {
+			return empty
+		}
+		return c.Sections[i]
+	}
+
+	for i := 0; i < SectionsPerChunk; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		for _, b := range section(i).Blocks {
+			dst = binary.LittleEndian.AppendUint16(dst, b)
+		}
+	}
+	for i := 0; i < SectionsPerChunk; i++ {
+		if mask&(1<<i) != 0 {
+			dst = append(dst, section(i).BlockLight[:]...)
+		}
+	}
+	if skyLight {
+		for i := 0; i < SectionsPerChunk; i++ {
+			if mask&(1<<i) != 0 {
+				dst = append(dst, section(i).SkyLight[:]...)
+			}
+		}
+	}
+	if groundUp {
+		dst = append(dst, c.Biomes[:]...)
+	}
+	return dst
+}
+
+// ReadData fills the sections in mask (and the biomes if groundUp) from
+// data in the Chunk Data format. data must be exactly ChunkDataSize long.
+// Sections outside mask are cleared if groundUp, and kept otherwise.
+func (c *ChunkColumn) ReadData(data []byte, mask uint16, skyLight, groundUp bool) error {
+	if want := ChunkDataSize(mask, skyLight, groundUp); len(data) != want {
+		return fmt.Errorf("%w: got %d bytes, want %d", ErrChunkDataSize, len(data), want)
+	}
+
+	for i := 0; i < SectionsPerChunk; i++ {
+		if mask&(1<<i) != 0 {
+			c.Sections[i] = &ChunkSection{}
+		} else if groundUp {
+			c.Sections[i] = nil
+		}
+	}
+
+	off := 0
+	for i := 0; i < SectionsPerChunk; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		blocks := &c.Sections[i].Blocks
+		for j := range blocks {
+			blocks[j] = binary.LittleEndian.Uint16(data[off:])
+			off += 2
+		}
+	}
+	for i := 0; i < SectionsPerChunk; i++ {
+		if mask&(1<<i) != 0 {
+			off += copy(c.Sections[i].BlockLight[:], data[off:])
+		}
+	}
+	if skyLight {
+		for i := 0; i < SectionsPerChunk; i++ {
+			if mask&(1<<i) != 0 {
+				off += copy(c.Sections[i].SkyLight[:], data[off:])
+			}
+		}
+	}
+	if groundUp {
+		copy(c.Biomes[:], data[off:])
+	}
+	return nil
+}
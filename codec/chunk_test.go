@@ -0,0 +1,123 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestChunkDataSize(t *testing.T) {
+	tests := []struct {
+		mask               uint16
+		skyLight, groundUp bool
+		want               int
+	}{
+		{0xFFFF, true, true, 196864}, // full overworld column
+		{0xFFFF, false, true, 164096},
+		{0x0000, true, true, 256}, // vanilla unload
+		{0x0001, false, false, 10240},
+		{0x0011, true, false, 24576},
+	}
+	for _, tt := range tests {
+		if got := ChunkDataSize(tt.mask, tt.skyLight, tt.groundUp); got != tt.want {
+			t.Errorf("ChunkDataSize(%#04x, %v, %v) = %d, want %d", tt.mask, tt.skyLight, tt.groundUp, got, tt.want)
+		}
+	}
+}
+
+// chunkVector is a column with sections 0 and 4 laid out the way vanilla
+// 1.8 writes it: all blocks, then all block light, then all sky light, then
+// the biomes.
+func chunkVector() []byte {
+	blocks0 := make([]byte, SectionVolume*2)
+	blocks0[0] = 0x10    // stone at 0,0,0
+	blocks0[1122] = 0x22 // grass:2 at 1,2,3
+	blocks4 := make([]byte, SectionVolume*2)
+	blocks4[8190] = 0x70 // bedrock at 15,15,15
+	light0 := make([]byte, NibbleArraySize)
+	light0[0] = 0x0F
+	light4 := make([]byte, NibbleArraySize)
+	sky := bytes.Repeat([]byte{0xFF}, NibbleArraySize)
+	biomes := bytes.Repeat([]byte{1}, BiomesPerChunk)
+	return bytes.Join([][]byte{blocks0, blocks4, light0, light4, sky, sky, biomes}, nil)
+}
+
+func TestChunkColumnData(t *testing.T) {
+	col := &ChunkColumn{}
+	col.SetBlock(0, 0, 0, BlockState(1, 0))
+	col.SetBlock(1, 2, 3, BlockState(2, 2))
+	col.SetBlock(15, 79, 15, BlockState(7, 0))
+	col.Sections[0].SetBlockLight(0, 0, 0, 15)
+	for i := range col.Biomes {
+		col.Biomes[i] = 1
+	}
+
+	want := chunkVector()
+	mask := col.PrimaryBitMask()
+	if mask != 0x0011 {
+		t.Fatalf("PrimaryBitMask = %#04x, want 0x0011", mask)
+	}
+	if got := col.AppendData(nil, mask, true, true); !bytes.Equal(got, want) {
+		t.Fatalf("AppendData differs from the vanilla layout (%d bytes, want %d)", len(got), len(want))
+	}
+
+	got := &ChunkColumn{}
+	if err := got.ReadData(want, mask, true, true); err != nil {
+		t.Fatal(err)
+	}
+	if got.Sections[0] == nil || got.Sections[4] == nil || got.Sections[1] != nil {
+		t.Fatal("ReadData did not fill exactly the sections in the mask")
+	}
+	for _, b := range []struct {
+		x, y, z int
+		want    uint16
+	}{
+		{0, 0, 0, BlockState(1, 0)},
+		{1, 2, 3, BlockState(2, 2)},
+		{15, 79, 15, BlockState(7, 0)},
+		{5, 5, 5, 0},
+	} {
+		if s := got.Block(b.x, b.y, b.z); s != b.want {
+			t.Errorf("Block(%d, %d, %d) = %#x, want %#x", b.x, b.y, b.z, s, b.want)
+		}
+	}
+	if l := got.Sections[0].BlockLightAt(0, 0, 0); l != 15 {
+		t.Errorf("BlockLightAt(0, 0, 0) = %d, want 15", l)
+	}
+	if l := got.Sections[4].SkyLightAt(3, 3, 3); l != 15 {
+		t.Errorf("SkyLightAt(3, 3, 3) = %d, want 15", l)
+	}
+	if got.Biome(7, 9) != 1 {
+		t.Errorf("Biome(7, 9) = %d, want 1", got.Biome(7, 9))
+	}
+}
+
+func TestChunkColumnPartialUpdate(t *testing.T) {
+	col := &ChunkColumn{}
+	if err := col.ReadData(chunkVector(), 0x0011, true, true); err != nil {
+		t.Fatal(err)
+	}
+	update := &ChunkColumn{}
+	update.SetBlock(0, 0, 0, BlockState(3, 0))
+	data := update.AppendData(nil, 0x0001, true, false)
+
+	if err := col.ReadData(data, 0x0001, true, false); err != nil {
+		t.Fatal(err)
+	}
+	if s := col.Block(0, 0, 0); s != BlockState(3, 0) {
+		t.Errorf("updated block = %#x, want dirt", s)
+	}
+	if s := col.Block(15, 79, 15); s != BlockState(7, 0) {
+		t.Errorf("section outside the update mask was lost, block = %#x", s)
+	}
+}
+
+func TestChunkColumnDataSize(t *testing.T) {
+	col := &ChunkColumn{}
+	data := chunkVector()
+	for _, d := range [][]byte{data[:len(data)-1], append(data, 0)} {
+		if err := col.ReadData(d, 0x0011, true, true); !errors.Is(err, ErrChunkDataSize) {
+			t.Errorf("ReadData(%d bytes) error = %v, want ErrChunkDataSize", len(d), err)
+		}
+	}
+}
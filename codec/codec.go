@@ -6,92 +6,194 @@ import (
 	"github.com/google/uuid"
 	"io"
 	"math"
+	"sync"
+	"unsafe"
 )
 
+// ============================
+//  Byte level helpers
+// ============================
+
+// scratch buffers are handed to readers and writers that implement neither
+// io.ByteReader nor io.ByteWriter. Passing a stack array to an interface
+// method would move it to the heap on every call; io.Reader and io.Writer
+// must not retain the slice, so the buffers can be reused.
+type scratch [MaxVarLongLen]byte
+
+var scratchPool = sync.Pool{New: func() any { return new(scratch) }}
+
+// readByte uses r's ReadByte when it has one (bytes.Reader, bufio.Reader),
+// which avoids allocating a buffer per call.
+func readByte(r io.Reader) (byte, error) {
+	if br, ok := r.(io.ByteReader); ok {
+		return br.ReadByte()
+	}
+	buf := scratchPool.Get().(*scratch)
+	defer scratchPool.Put(buf)
+	_, err := io.ReadFull(r, buf[:1])
+	return buf[0], err
+}
+
+// readUint reads an n byte big-endian unsigned integer.
+func readUint(r io.Reader, n int) (uint64, error) {
+	var v uint64
+	if br, ok := r.(io.ByteReader); ok {
+		for i := 0; i < n; i++ {
+			b, err := br.ReadByte()
+			if err != nil {
+				if i > 0 && err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return 0, err
+			}
+			v = v<<8 | uint64(b)
+		}
+		return v, nil
+	}
+	buf := scratchPool.Get().(*scratch)
+	defer scratchPool.Put(buf)
+	if _, err := io.ReadFull(r, buf[:n]); err != nil {
+		return 0, err
+	}
+	for _, b := range buf[:n] {
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+func writeByte(w io.Writer, b byte) error {
+	if bw, ok := w.(io.ByteWriter); ok {
+		return bw.WriteByte(b)
+	}
+	buf := scratchPool.Get().(*scratch)
+	defer scratchPool.Put(buf)
+	buf[0] = b
+	_, err := w.Write(buf[:1])
+	return err
+}
+
+// writeUint writes the low n bytes of v big-endian. Writers that implement
+// io.ByteWriter (bytes.Buffer, bufio.Writer) are written to byte by byte.
+func writeUint(w io.Writer, v uint64, n int) error {
+	if bw, ok := w.(io.ByteWriter); ok {
+		for i := n - 1; i >= 0; i-- {
+			if err := bw.WriteByte(byte(v >> (8 * i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	buf := scratchPool.Get().(*scratch)
+	defer scratchPool.Put(buf)
+	for i := 0; i < n; i++ {
+		buf[i] = byte(v >> (8 * (n - 1 - i)))
+	}
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// writeVarUint writes v as a VarInt/VarLong.
+func writeVarUint(w io.Writer, v uint64) error {
+	if bw, ok := w.(io.ByteWriter); ok {
+		for v >= 0x80 {
+			if err := bw.WriteByte(byte(v) | 0x80); err != nil {
+				return err
+			}
+			v >>= 7
+		}
+		return bw.WriteByte(byte(v))
+	}
+	buf := scratchPool.Get().(*scratch)
+	defer scratchPool.Put(buf)
+	_, err := w.Write(AppendVarLong(buf[:0], VarLong(v)))
+	return err
+}
+
 // ============================
 //  VarInt / VarLong
 // ============================
 
+const (
+	MaxVarIntLen  = 5
+	MaxVarLongLen = 10
+)
+
+var (
+	ErrVarIntTooLong  = errors.New("varint too long")
+	ErrVarLongTooLong = errors.New("varlong too long")
+)
+
 type VarInt int32
 
 func ReadVarInt(r io.Reader) (VarInt, error) {
-	var num VarInt
-	var shift uint
-	for {
-		var b [1]byte
-		if _, err := r.Read(b[:]); err != nil {
+	var num uint32
+	for i := 0; i < MaxVarIntLen; i++ {
+		b, err := readByte(r)
+		if err != nil {
 			return 0, err
 		}
-		num |= VarInt(b[0]&0x7F) << shift
-
-		if (b[0] & 0x80) == 0 {
-			break
-		}
-		shift += 7
-		if shift > 35 {
-			return 0, errors.New("varint too long")
+		num |= uint32(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return VarInt(num), nil
 		}
 	}
-	return num, nil
+	return 0, ErrVarIntTooLong
 }
 
 func WriteVarInt(w io.Writer, value VarInt) error {
+	return writeVarUint(w, uint64(uint32(value)))
+}
+
+// AppendVarInt appends the encoding of value to dst.
+func AppendVarInt(dst []byte, value VarInt) []byte {
 	u := uint32(value)
-	for {
-		b := byte(u & 0x7F)
+	for u >= 0x80 {
+		dst = append(dst, byte(u)|0x80)
 		u >>= 7
-		if u != 0 {
-			b |= 0x80
-		}
-		if _, err := w.Write([]byte{b}); err != nil {
-			return err
-		}
-		if u == 0 {
-			break
-		}
 	}
-	return nil
+	return append(dst, byte(u))
+}
+
+// VarIntSize returns the number of bytes value takes on the wire.
+func VarIntSize(value VarInt) int {
+	u := uint32(value)
+	n := 1
+	for u >= 0x80 {
+		u >>= 7
+		n++
+	}
+	return n
 }
 
 type VarLong int64
 
 func ReadVarLong(r io.Reader) (VarLong, error) {
-	var num VarLong
-	var shift uint
-	for {
-		var b [1]byte
-		if _, err := r.Read(b[:]); err != nil {
+	var num uint64
+	for i := 0; i < MaxVarLongLen; i++ {
+		b, err := readByte(r)
+		if err != nil {
 			return 0, err
 		}
-		num |= VarLong(b[0]&0x7F) << shift
-
-		if (b[0] & 0x80) == 0 {
-			break
-		}
-		shift += 7
-		if shift > 70 {
-			return 0, errors.New("varlong too long")
+		num |= uint64(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return VarLong(num), nil
 		}
 	}
-	return num, nil
+	return 0, ErrVarLongTooLong
 }
 
-// WriteVarLong TODO: prevent infinit loof when negative
 func WriteVarLong(w io.Writer, value VarLong) error {
-	for {
-		b := byte(value & 0x7F)
-		value >>= 7
-		if value != 0 {
-			b |= 0x80
-		}
-		if _, err := w.Write([]byte{b}); err != nil {
-			return err
-		}
-		if value == 0 {
-			break
-		}
+	return writeVarUint(w, uint64(value))
+}
+
+// AppendVarLong appends the encoding of value to dst.
+func AppendVarLong(dst []byte, value VarLong) []byte {
+	u := uint64(value)
+	for u >= 0x80 {
+		dst = append(dst, byte(u)|0x80)
+		u >>= 7
 	}
-	return nil
+	return append(dst, byte(u))
 }
 
 // ============================
@@ -99,130 +201,204 @@ func WriteVarLong(w io.Writer, value VarLong) error {
 // ============================
 
 func ReadBool(r io.Reader) (bool, error) {
-	var b [1]byte
-	_, err := r.Read(b[:])
-	return b[0] != 0, err
+	b, err := readByte(r)
+	return b != 0, err
 }
 
 func WriteBool(w io.Writer, v bool) error {
 	if v {
-		_, err := w.Write([]byte{1})
-		return err
+		return writeByte(w, 1)
 	}
-	_, err := w.Write([]byte{0})
-	return err
+	return writeByte(w, 0)
+}
+
+func AppendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 1)
+	}
+	return append(dst, 0)
 }
 
 func ReadByte(r io.Reader) (int8, error) {
-	var b [1]byte
-	_, err := r.Read(b[:])
-	return int8(b[0]), err
+	b, err := readByte(r)
+	return int8(b), err
 }
 func WriteByte(w io.Writer, v int8) error {
-	_, err := w.Write([]byte{byte(v)})
-	return err
+	return writeByte(w, byte(v))
+}
+func AppendByte(dst []byte, v int8) []byte {
+	return append(dst, byte(v))
 }
 
 func ReadUByte(r io.Reader) (uint8, error) {
-	var b [1]byte
-	_, err := r.Read(b[:])
-	return b[0], err
+	return readByte(r)
 }
 func WriteUByte(w io.Writer, v uint8) error {
-	_, err := w.Write([]byte{v})
-	return err
+	return writeByte(w, v)
+}
+func AppendUByte(dst []byte, v uint8) []byte {
+	return append(dst, v)
 }
 
 func ReadShort(r io.Reader) (int16, error) {
-	var val int16
-	err := binary.Read(r, binary.BigEndian, &val)
-	return val, err
+	v, err := readUint(r, 2)
+	return int16(v), err
 }
 func WriteShort(w io.Writer, v int16) error {
-	return binary.Write(w, binary.BigEndian, v)
+	return WriteUShort(w, uint16(v))
+}
+func AppendShort(dst []byte, v int16) []byte {
+	return binary.BigEndian.AppendUint16(dst, uint16(v))
 }
 
 func ReadUShort(r io.Reader) (uint16, error) {
-	var val uint16
-	err := binary.Read(r, binary.BigEndian, &val)
-	return val, err
+	v, err := readUint(r, 2)
+	return uint16(v), err
 }
 func WriteUShort(w io.Writer, v uint16) error {
-	return binary.Write(w, binary.BigEndian, v)
+	return writeUint(w, uint64(v), 2)
+}
+func AppendUShort(dst []byte, v uint16) []byte {
+	return binary.BigEndian.AppendUint16(dst, v)
 }
 
 func ReadInt(r io.Reader) (int32, error) {
-	var val int32
-	err := binary.Read(r, binary.BigEndian, &val)
-	return val, err
+	v, err := readUint(r, 4)
+	return int32(v), err
 }
 func WriteInt(w io.Writer, v int32) error {
-	return binary.Write(w, binary.BigEndian, v)
+	return writeUint(w, uint64(uint32(v)), 4)
+}
+func AppendInt(dst []byte, v int32) []byte {
+	return binary.BigEndian.AppendUint32(dst, uint32(v))
 }
 
 func ReadLong(r io.Reader) (int64, error) {
-	var val int64
-	err := binary.Read(r, binary.BigEndian, &val)
-	return val, err
+	v, err := readUint(r, 8)
+	return int64(v), err
 }
 func WriteLong(w io.Writer, v int64) error {
-	return binary.Write(w, binary.BigEndian, v)
+	return writeUint(w, uint64(v), 8)
+}
+func AppendLong(dst []byte, v int64) []byte {
+	return binary.BigEndian.AppendUint64(dst, uint64(v))
 }
 
 func ReadFloat(r io.Reader) (float32, error) {
-	var bits uint32
-	err := binary.Read(r, binary.BigEndian, &bits)
-	return math.Float32frombits(bits), err
+	bits, err := readUint(r, 4)
+	return math.Float32frombits(uint32(bits)), err
 }
 func WriteFloat(w io.Writer, v float32) error {
-	bits := math.Float32bits(v)
-	return binary.Write(w, binary.BigEndian, bits)
+	return WriteInt(w, int32(math.Float32bits(v)))
+}
+func AppendFloat(dst []byte, v float32) []byte {
+	return binary.BigEndian.AppendUint32(dst, math.Float32bits(v))
 }
 
 func ReadDouble(r io.Reader) (float64, error) {
-	var bits uint64
-	err := binary.Read(r, binary.BigEndian, &bits)
+	bits, err := readUint(r, 8)
 	return math.Float64frombits(bits), err
 }
 func WriteDouble(w io.Writer, v float64) error {
-	bits := math.Float64bits(v)
-	return binary.Write(w, binary.BigEndian, bits)
+	return WriteLong(w, int64(math.Float64bits(v)))
+}
+func AppendDouble(dst []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(dst, math.Float64bits(v))
 }
 
 // ============================
 //  Strings
 // ============================
 
+var ErrInvalidLength = errors.New("invalid length prefix")
+
+// checkLength rejects negative lengths and, if r knows how many bytes are
+// left (e.g. *bytes.Reader), lengths that cannot possibly be satisfied, so
+// that nothing is allocated for them.
+func checkLength(r io.Reader, length VarInt) error {
+	if length < 0 {
+		return ErrInvalidLength
+	}
+	if lr, ok := r.(interface{ Len() int }); ok && int(length) > lr.Len() {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// MaxStringLength is the 1.8 limit for strings in bytes: 32767 characters of
+// up to four bytes each.
+const MaxStringLength = 32767 * 4
+
 func ReadString(r io.Reader) (string, error) {
+	return ReadStringMax(r, MaxStringLength)
+}
+
+// ReadStringMax is like ReadString but rejects strings longer than max bytes
+// before reading them.
+func ReadStringMax(r io.Reader, max int) (string, error) {
 	length, err := ReadVarInt(r)
 	if err != nil {
 		return "", err
 	}
+	if int(length) > max {
+		return "", ErrStringTooLong
+	}
+	buf, err := readLength(r, length)
+	if err != nil || len(buf) == 0 {
+		return "", err
+	}
+	// buf is not used elsewhere, so the string can share it
+	return unsafe.String(unsafe.SliceData(buf), len(buf)), nil
+}
+
+// readLength reads length bytes after checking the length with checkLength.
+func readLength(r io.Reader, length VarInt) ([]byte, error) {
+	if err := checkLength(r, length); err != nil {
+		return nil, err
+	}
 	buf := make([]byte, length)
-	_, err = io.ReadFull(r, buf)
-	return string(buf), err
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
 }
 
 func WriteString(w io.Writer, s string) error {
 	if err := WriteVarInt(w, VarInt(len(s))); err != nil {
 		return err
 	}
-	_, err := w.Write([]byte(s))
+	_, err := io.WriteString(w, s)
 	return err
 }
 
+func AppendString(dst []byte, s string) []byte {
+	dst = AppendVarInt(dst, VarInt(len(s)))
+	return append(dst, s...)
+}
+
 // ============================
 //  Byte Arrays
 // ============================
 
+// ReadByteArray reads a VarInt prefixed byte array of at most
+// DefaultMaxFrameSize bytes, as no longer one fits in a packet.
 func ReadByteArray(r io.Reader) ([]byte, error) {
+	return ReadByteArrayMax(r, DefaultMaxFrameSize)
+}
+
+// ReadByteArrayMax is like ReadByteArray but rejects arrays longer than max.
+func ReadByteArrayMax(r io.Reader, max int) ([]byte, error) {
 	length, err := ReadVarInt(r)
 	if err != nil {
 		return nil, err
 	}
-	buf := make([]byte, length)
-	_, err = io.ReadFull(r, buf)
-	return buf, err
+	if int(length) > max {
+		return nil, ErrInvalidLength
+	}
+	return readLength(r, length)
 }
 
 func WriteByteArray(w io.Writer, data []byte) error {
@@ -233,12 +409,19 @@ func WriteByteArray(w io.Writer, data []byte) error {
 	return err
 }
 
+func AppendByteArray(dst []byte, data []byte) []byte {
+	dst = AppendVarInt(dst, VarInt(len(data)))
+	return append(dst, data...)
+}
+
 // ============================
 //  UUIDs
 // ============================
 
 func ReadUUID(r io.Reader) (uuid.UUID, error) {
-	return uuid.NewRandomFromReader(r)
+	var u uuid.UUID
+	_, err := io.ReadFull(r, u[:])
+	return u, err
 }
 
 func WriteUUID(w io.Writer, uuid uuid.UUID) error {
@@ -246,14 +429,16 @@ func WriteUUID(w io.Writer, uuid uuid.UUID) error {
 	return err
 }
 
+func AppendUUID(dst []byte, uuid uuid.UUID) []byte {
+	return append(dst, uuid[:]...)
+}
+
 type Angle uint8
 
 func ReadAngle(r io.Reader) (Angle, error) {
-	var b [1]byte
-	_, err := r.Read(b[:])
-	return Angle(b[0]), err
+	b, err := readByte(r)
+	return Angle(b), err
 }
 func WriteAngle(w io.Writer, v Angle) error {
-	_, err := w.Write([]byte{byte(v)})
-	return err
+	return writeByte(w, byte(v))
 }
@@ -0,0 +1,447 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+// plainReader and plainWriter hide the io.ByteReader/io.ByteWriter fast paths.
+type plainReader struct{ r io.Reader }
+
+func (p plainReader) Read(b []byte) (int, error) { return p.r.Read(b) }
+
+type plainWriter struct{ w io.Writer }
+
+func (p plainWriter) Write(b []byte) (int, error) { return p.w.Write(b) }
+
+var varIntVectors = []struct {
+	value VarInt
+	wire  []byte
+}{
+	{0, []byte{0x00}},
+	{1, []byte{0x01}},
+	{127, []byte{0x7f}},
+	{128, []byte{0x80, 0x01}},
+	{255, []byte{0xff, 0x01}},
+	{25565, []byte{0xdd, 0xc7, 0x01}},
+	{2097151, []byte{0xff, 0xff, 0x7f}},
+	{math.MaxInt32, []byte{0xff, 0xff, 0xff, 0xff, 0x07}},
+	{-1, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+	{math.MinInt32, []byte{0x80, 0x80, 0x80, 0x80, 0x08}},
+}
+
+var varLongVectors = []struct {
+	value VarLong
+	wire  []byte
+}{
+	{0, []byte{0x00}},
+	{2147483647, []byte{0xff, 0xff, 0xff, 0xff, 0x07}},
+	{math.MaxInt64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+	{-1, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	{math.MinInt64, []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+}
+
+func TestVarInt(t *testing.T) {
+	for _, tt := range varIntVectors {
+		if got := AppendVarInt(nil, tt.value); !bytes.Equal(got, tt.wire) {
+			t.Errorf("AppendVarInt(%d) = % x, want % x", tt.value, got, tt.wire)
+		}
+		if got := VarIntSize(tt.value); got != len(tt.wire) {
+			t.Errorf("VarIntSize(%d) = %d, want %d", tt.value, got, len(tt.wire))
+		}
+		for _, w := range []func(*bytes.Buffer) io.Writer{
+			func(b *bytes.Buffer) io.Writer { return b },
+			func(b *bytes.Buffer) io.Writer { return plainWriter{b} },
+		} {
+			buf := &bytes.Buffer{}
+			if err := WriteVarInt(w(buf), tt.value); err != nil || !bytes.Equal(buf.Bytes(), tt.wire) {
+				t.Errorf("WriteVarInt(%d) = % x, %v", tt.value, buf.Bytes(), err)
+			}
+		}
+		for _, r := range []io.Reader{bytes.NewReader(tt.wire), plainReader{bytes.NewReader(tt.wire)}} {
+			if got, err := ReadVarInt(r); err != nil || got != tt.value {
+				t.Errorf("ReadVarInt(% x) = %d, %v", tt.wire, got, err)
+			}
+		}
+	}
+}
+
+func TestVarLong(t *testing.T) {
+	for _, tt := range varLongVectors {
+		if got := AppendVarLong(nil, tt.value); !bytes.Equal(got, tt.wire) {
+			t.Errorf("AppendVarLong(%d) = % x, want % x", tt.value, got, tt.wire)
+		}
+		buf := &bytes.Buffer{}
+		if err := WriteVarLong(plainWriter{buf}, tt.value); err != nil || !bytes.Equal(buf.Bytes(), tt.wire) {
+			t.Errorf("WriteVarLong(%d) = % x, %v", tt.value, buf.Bytes(), err)
+		}
+		if got, err := ReadVarLong(bytes.NewReader(tt.wire)); err != nil || got != tt.value {
+			t.Errorf("ReadVarLong(% x) = %d, %v", tt.wire, got, err)
+		}
+	}
+}
+
+func TestVarIntTooLong(t *testing.T) {
+	long := []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x01}
+	if _, err := ReadVarInt(bytes.NewReader(long)); !errors.Is(err, ErrVarIntTooLong) {
+		t.Errorf("ReadVarInt err = %v, want ErrVarIntTooLong", err)
+	}
+	if _, err := ReadVarLong(bytes.NewReader(bytes.Repeat([]byte{0x80}, 11))); !errors.Is(err, ErrVarLongTooLong) {
+		t.Errorf("ReadVarLong err = %v, want ErrVarLongTooLong", err)
+	}
+}
+
+func TestPrimitives(t *testing.T) {
+	var dst []byte
+	dst = AppendBool(dst, true)
+	dst = AppendByte(dst, -2)
+	dst = AppendUByte(dst, 0xfe)
+	dst = AppendShort(dst, -2)
+	dst = AppendUShort(dst, 25565)
+	dst = AppendInt(dst, 0x01020304)
+	dst = AppendLong(dst, -2)
+	dst = AppendFloat(dst, 1)
+	dst = AppendDouble(dst, -2)
+	dst = AppendString(dst, "hi")
+	want := []byte{
+		0x01,
+		0xfe,
+		0xfe,
+		0xff, 0xfe,
+		0x63, 0xdd,
+		0x01, 0x02, 0x03, 0x04,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe,
+		0x3f, 0x80, 0x00, 0x00,
+		0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x02, 'h', 'i',
+	}
+	if !bytes.Equal(dst, want) {
+		t.Fatalf("Append* = % x\nwant      % x", dst, want)
+	}
+
+	for _, w := range []func(*bytes.Buffer) io.Writer{
+		func(b *bytes.Buffer) io.Writer { return b },
+		func(b *bytes.Buffer) io.Writer { return plainWriter{b} },
+	} {
+		buf := &bytes.Buffer{}
+		out := w(buf)
+		WriteBool(out, true)
+		WriteByte(out, -2)
+		WriteUByte(out, 0xfe)
+		WriteShort(out, -2)
+		WriteUShort(out, 25565)
+		WriteInt(out, 0x01020304)
+		WriteLong(out, -2)
+		WriteFloat(out, 1)
+		WriteDouble(out, -2)
+		WriteString(out, "hi")
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Fatalf("Write* = % x\nwant     % x", buf.Bytes(), want)
+		}
+	}
+
+	for _, r := range []io.Reader{bytes.NewReader(want), plainReader{bytes.NewReader(want)}} {
+		if v, err := ReadBool(r); err != nil || !v {
+			t.Errorf("ReadBool = %v, %v", v, err)
+		}
+		if v, err := ReadByte(r); err != nil || v != -2 {
+			t.Errorf("ReadByte = %v, %v", v, err)
+		}
+		if v, err := ReadUByte(r); err != nil || v != 0xfe {
+			t.Errorf("ReadUByte = %v, %v", v, err)
+		}
+		if v, err := ReadShort(r); err != nil || v != -2 {
+			t.Errorf("ReadShort = %v, %v", v, err)
+		}
+		if v, err := ReadUShort(r); err != nil || v != 25565 {
+			t.Errorf("ReadUShort = %v, %v", v, err)
+		}
+		if v, err := ReadInt(r); err != nil || v != 0x01020304 {
+			t.Errorf("ReadInt = %v, %v", v, err)
+		}
+		if v, err := ReadLong(r); err != nil || v != -2 {
+			t.Errorf("ReadLong = %v, %v", v, err)
+		}
+		if v, err := ReadFloat(r); err != nil || v != 1 {
+			t.Errorf("ReadFloat = %v, %v", v, err)
+		}
+		if v, err := ReadDouble(r); err != nil || v != -2 {
+			t.Errorf("ReadDouble = %v, %v", v, err)
+		}
+		if v, err := ReadString(r); err != nil || v != "hi" {
+			t.Errorf("ReadString = %q, %v", v, err)
+		}
+	}
+}
+
+func TestShortReads(t *testing.T) {
+	if _, err := ReadInt(bytes.NewReader([]byte{1, 2})); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadInt err = %v, want ErrUnexpectedEOF", err)
+	}
+	if _, err := ReadString(bytes.NewReader([]byte{5, 'a'})); err == nil {
+		t.Error("ReadString of a truncated string did not fail")
+	}
+	if _, err := ReadString(plainReader{bytes.NewReader([]byte{5, 'a'})}); err == nil {
+		t.Error("ReadString of a truncated string did not fail")
+	}
+}
+
+func TestLengthLimits(t *testing.T) {
+	// only the prefixes are sent; plainReader cannot tell that the rest is
+	// missing, so the limit has to reject them before anything is read
+	prefix := func(n int) io.Reader { return plainReader{bytes.NewReader(AppendVarInt(nil, VarInt(n)))} }
+	if _, err := ReadString(prefix(MaxStringLength + 1)); !errors.Is(err, ErrStringTooLong) {
+		t.Errorf("ReadString error = %v, want ErrStringTooLong", err)
+	}
+	if _, err := ReadStringMax(prefix(17), 16); !errors.Is(err, ErrStringTooLong) {
+		t.Errorf("ReadStringMax error = %v, want ErrStringTooLong", err)
+	}
+	if _, err := ReadByteArray(prefix(DefaultMaxFrameSize + 1)); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("ReadByteArray error = %v, want ErrInvalidLength", err)
+	}
+	if _, err := ReadByteArrayMax(prefix(17), 16); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("ReadByteArrayMax error = %v, want ErrInvalidLength", err)
+	}
+
+	long := string(bytes.Repeat([]byte{'a'}, MaxStringLength))
+	if s, err := ReadString(plainReader{bytes.NewReader(AppendString(nil, long))}); err != nil || s != long {
+		t.Errorf("ReadString of %d bytes = %d bytes, %v", len(long), len(s), err)
+	}
+	if s, err := ReadStringMax(bytes.NewReader(AppendString(nil, "abc")), 3); err != nil || s != "abc" {
+		t.Errorf("ReadStringMax = %q, %v", s, err)
+	}
+}
+
+func TestChatLength(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		ok   bool
+	}{
+		{"ascii", strings.Repeat("a", MaxChatLength), true},
+		{"ascii over", strings.Repeat("a", MaxChatLength+1), false},
+		// two UTF-8 bytes, one UTF-16 code unit each
+		{"latin", strings.Repeat("é", MaxChatLength), true},
+		{"latin over", strings.Repeat("é", MaxChatLength+1), false},
+		// four UTF-8 bytes, a surrogate pair in UTF-16
+		{"emoji", strings.Repeat("😀", MaxChatLength/2), true},
+		{"emoji over", strings.Repeat("😀", MaxChatLength/2) + "😀", false},
+	}
+	for _, tt := range tests {
+		want := error(nil)
+		if !tt.ok {
+			want = ErrStringTooLong
+		}
+		if err := WriteChat(&bytes.Buffer{}, Chat(tt.s)); err != want {
+			t.Errorf("%s: WriteChat error = %v, want %v", tt.name, err, want)
+		}
+		if _, err := AppendChat(nil, Chat(tt.s)); err != want {
+			t.Errorf("%s: AppendChat error = %v, want %v", tt.name, err, want)
+		}
+		if _, err := ReadChat(bytes.NewReader(AppendString(nil, tt.s))); err != want {
+			t.Errorf("%s: ReadChat error = %v, want %v", tt.name, err, want)
+		}
+	}
+}
+
+func TestPosition(t *testing.T) {
+	// 1.8 packs x (26 bits), y (12 bits), z (26 bits) from the top
+	wire := []byte{0x46, 0x07, 0x63, 0x0c, 0xfe, 0xc1, 0x5b, 0x48}
+	p := Position{X: 18357644, Y: 831, Z: -20882616}
+	if got := AppendPosition(nil, p); !bytes.Equal(got, wire) {
+		t.Errorf("AppendPosition = % x, want % x", got, wire)
+	}
+	if got, err := ReadPos(bytes.NewReader(wire)); err != nil || got != p {
+		t.Errorf("ReadPos = %+v, %v", got, err)
+	}
+}
+
+func TestZeroAllocs(t *testing.T) {
+	data := AppendVarInt(nil, 25565)
+	data = AppendVarLong(data, -1)
+	data = AppendUShort(data, 25565)
+	data = AppendInt(data, 1)
+	data = AppendLong(data, 1)
+	data = AppendFloat(data, 1)
+	data = AppendDouble(data, 1)
+	data = AppendBool(data, true)
+	r := bytes.NewReader(data)
+	read := testing.AllocsPerRun(100, func() {
+		r.Reset(data)
+		ReadVarInt(r)
+		ReadVarLong(r)
+		ReadUShort(r)
+		ReadInt(r)
+		ReadLong(r)
+		ReadFloat(r)
+		ReadDouble(r)
+		ReadBool(r)
+	})
+	if read != 0 {
+		t.Errorf("reads from a bytes.Reader allocate %v times", read)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.Grow(64)
+	write := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		WriteVarInt(buf, 25565)
+		WriteVarLong(buf, -1)
+		WriteUShort(buf, 25565)
+		WriteInt(buf, 1)
+		WriteLong(buf, 1)
+		WriteFloat(buf, 1)
+		WriteDouble(buf, 1)
+		WriteBool(buf, true)
+		WriteString(buf, "hello")
+	})
+	if write != 0 {
+		t.Errorf("writes to a bytes.Buffer allocate %v times", write)
+	}
+
+	dst := make([]byte, 0, 64)
+	appends := testing.AllocsPerRun(100, func() {
+		b := AppendVarInt(dst, 25565)
+		b = AppendVarLong(b, -1)
+		b = AppendLong(b, 1)
+		b = AppendDouble(b, 1)
+		b = AppendString(b, "hello")
+		AppendPosition(b, Position{X: 1, Y: 2, Z: 3})
+	})
+	if appends != 0 {
+		t.Errorf("appends allocate %v times", appends)
+	}
+
+	str := AppendString(nil, "hello world")
+	sr := bytes.NewReader(str)
+	strAllocs := testing.AllocsPerRun(100, func() {
+		sr.Reset(str)
+		ReadString(sr)
+	})
+	if strAllocs > 1 {
+		t.Errorf("ReadString allocates %v times, want only the string", strAllocs)
+	}
+}
+
+func BenchmarkReadVarInt(b *testing.B) {
+	data := AppendVarInt(nil, 2097151)
+	r := bytes.NewReader(data)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		if _, err := ReadVarInt(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadVarIntPlain(b *testing.B) {
+	data := AppendVarInt(nil, 2097151)
+	br := bytes.NewReader(data)
+	var r io.Reader = plainReader{br}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		br.Reset(data)
+		if _, err := ReadVarInt(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteVarInt(b *testing.B) {
+	buf := &bytes.Buffer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		WriteVarInt(buf, 2097151)
+	}
+}
+
+func BenchmarkWriteVarIntPlain(b *testing.B) {
+	var w io.Writer = plainWriter{io.Discard}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WriteVarInt(w, 2097151)
+	}
+}
+
+func BenchmarkAppendVarInt(b *testing.B) {
+	dst := make([]byte, 0, MaxVarIntLen)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		AppendVarInt(dst, 2097151)
+	}
+}
+
+func BenchmarkReadLong(b *testing.B) {
+	data := AppendLong(nil, math.MaxInt64)
+	r := bytes.NewReader(data)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		if _, err := ReadLong(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteLongPlain(b *testing.B) {
+	var w io.Writer = plainWriter{io.Discard}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WriteLong(w, math.MaxInt64)
+	}
+}
+
+func BenchmarkAppendLong(b *testing.B) {
+	dst := make([]byte, 0, 8)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		AppendLong(dst, math.MaxInt64)
+	}
+}
+
+func BenchmarkReadDouble(b *testing.B) {
+	data := AppendDouble(nil, math.Pi)
+	r := bytes.NewReader(data)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		if _, err := ReadDouble(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadString(b *testing.B) {
+	data := AppendString(nil, "minecraft:overworld")
+	r := bytes.NewReader(data)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		if _, err := ReadString(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendString(b *testing.B) {
+	dst := make([]byte, 0, 32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		AppendString(dst, "minecraft:overworld")
+	}
+}
+
+func BenchmarkAppendPosition(b *testing.B) {
+	dst := make([]byte, 0, 8)
+	p := Position{X: 18357644, Y: 831, Z: -20882616}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		AppendPosition(dst, p)
+	}
+}
@@ -0,0 +1,135 @@
+package codec
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CompressionDisabled is the threshold value used when the connection has not
+// (yet) received a Set Compression packet.
+const CompressionDisabled = -1
+
+const (
+	// DefaultMaxFrameSize is the vanilla limit of 2 MiB: the largest length a
+	// three byte VarInt can express.
+	DefaultMaxFrameSize = 1<<21 - 1
+	// MaxUncompressedSize is the vanilla limit for the data length of a
+	// compressed frame.
+	MaxUncompressedSize = 1 << 23
+)
+
+var ErrBadlyCompressed = errors.New("badly compressed packet")
+
+// FrameSizeError is returned for negative or oversized frame lengths. It is
+// detected before anything is allocated.
+type FrameSizeError struct {
+	Length int
+	Max    int
+}
+
+var _ error = (*FrameSizeError)(nil)
+
+func (e *FrameSizeError) Error() string {
+	if e.Length < 0 {
+		return fmt.Sprintf("negative frame length %d", e.Length)
+	}
+	return fmt.Sprintf("frame length %d exceeds maximum of %d", e.Length, e.Max)
+}
+
+// ReadFrame reads one length-prefixed frame from r and returns the
+// uncompressed packet ID and data. A negative threshold reads the plain
+// `length | id | data` format, otherwise the compressed
+// `length | data length | zlib(id | data)` format is expected.
+func ReadFrame(r io.Reader, threshold int) ([]byte, error) {
+	return ReadLimitedFrame(r, threshold, DefaultMaxFrameSize)
+}
+
+// ReadLimitedFrame is like ReadFrame but rejects frames longer than maxSize
+// bytes on the wire with a *FrameSizeError.
+func ReadLimitedFrame(r io.Reader, threshold int, maxSize int) ([]byte, error) {
+	length, err := ReadVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 || int(length) > maxSize {
+		return nil, &FrameSizeError{Length: int(length), Max: maxSize}
+	}
+
+	buf := make([]byte, length)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	if threshold < 0 {
+		return buf, nil
+	}
+
+	br := bytes.NewReader(buf)
+	dataLength, err := ReadVarInt(br)
+	if err != nil {
+		return nil, err
+	}
+	if dataLength == 0 {
+		return buf[len(buf)-br.Len():], nil
+	}
+	if int(dataLength) < threshold {
+		return nil, fmt.Errorf("%w: data length %d is below threshold %d", ErrBadlyCompressed, dataLength, threshold)
+	}
+	if dataLength < 0 || dataLength > MaxUncompressedSize {
+		return nil, &FrameSizeError{Length: int(dataLength), Max: MaxUncompressedSize}
+	}
+
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data := make([]byte, dataLength)
+	if _, err = io.ReadFull(zr, data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadlyCompressed, err)
+	}
+	return data, nil
+}
+
+// WriteFrame frames the packet ID and data in payload and writes it to w in a
+// single Write call. See ReadFrame for the meaning of threshold.
+func WriteFrame(w io.Writer, payload []byte, threshold int) error {
+	frame, err := AppendFrame(nil, payload, threshold)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(frame)
+	return err
+}
+
+// AppendFrame appends the framed form of payload to dst.
+func AppendFrame(dst []byte, payload []byte, threshold int) ([]byte, error) {
+	if threshold < 0 {
+		dst = AppendVarInt(dst, VarInt(len(payload)))
+		return append(dst, payload...), nil
+	}
+	if len(payload) < threshold {
+		dst = AppendVarInt(dst, VarInt(len(payload)+1))
+		dst = append(dst, 0)
+		return append(dst, payload...), nil
+	}
+
+	body := &bytes.Buffer{}
+	if err := WriteVarInt(body, VarInt(len(payload))); err != nil {
+		return nil, err
+	}
+	zw := zlib.NewWriter(body)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	dst = AppendVarInt(dst, VarInt(body.Len()))
+	return append(dst, body.Bytes()...), nil
+}
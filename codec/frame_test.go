@@ -0,0 +1,104 @@
+package codec
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFrameThreshold(t *testing.T) {
+	const threshold = 64
+	tests := []struct {
+		name       string
+		size       int
+		threshold  int
+		compressed bool
+	}{
+		{"disabled", 100, CompressionDisabled, false},
+		{"empty", 0, threshold, false},
+		{"below", threshold - 1, threshold, false},
+		{"at", threshold, threshold, true},
+		{"above", threshold + 1, threshold, true},
+		{"zero threshold", 1, 0, true},
+	}
+	for _, tt := range tests {
+		payload := bytes.Repeat([]byte{0x2a}, tt.size)
+		frame, err := AppendFrame(nil, payload, tt.threshold)
+		if err != nil {
+			t.Fatalf("%s: AppendFrame: %v", tt.name, err)
+		}
+
+		r := bytes.NewReader(frame)
+		length, _ := ReadVarInt(r)
+		if int(length) != r.Len() {
+			t.Errorf("%s: frame length %d, %d bytes follow", tt.name, length, r.Len())
+		}
+		switch {
+		case tt.threshold < 0:
+			if !bytes.Equal(frame[len(frame)-r.Len():], payload) {
+				t.Errorf("%s: frame = % x", tt.name, frame)
+			}
+		case tt.compressed:
+			if dataLength, _ := ReadVarInt(r); int(dataLength) != tt.size {
+				t.Errorf("%s: data length = %d, want %d", tt.name, dataLength, tt.size)
+			}
+		default:
+			// uncompressed frames mark the data length as 0
+			if dataLength, _ := ReadVarInt(r); dataLength != 0 || !bytes.Equal(frame[len(frame)-r.Len():], payload) {
+				t.Errorf("%s: frame = % x", tt.name, frame)
+			}
+		}
+
+		got, err := ReadFrame(bytes.NewReader(frame), tt.threshold)
+		if err != nil || !bytes.Equal(got, payload) {
+			t.Errorf("%s: ReadFrame = % x, %v", tt.name, got, err)
+		}
+	}
+}
+
+// compressedFrame builds a compressed frame claiming dataLength bytes.
+func compressedFrame(dataLength int, payload []byte) []byte {
+	body := AppendVarInt(nil, VarInt(dataLength))
+	buf := &bytes.Buffer{}
+	zw := zlib.NewWriter(buf)
+	zw.Write(payload)
+	zw.Close()
+	body = append(body, buf.Bytes()...)
+	return append(AppendVarInt(nil, VarInt(len(body))), body...)
+}
+
+func TestReadFrameRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		frame     []byte
+		threshold int
+		want      error // nil for a *FrameSizeError
+	}{
+		{"negative length", AppendVarInt(nil, -1), CompressionDisabled, nil},
+		{"oversized", AppendVarInt(nil, DefaultMaxFrameSize+1), CompressionDisabled, nil},
+		{"oversized data length", compressedFrame(MaxUncompressedSize+1, []byte{1}), 64, nil},
+		{"negative data length", compressedFrame(-1, []byte{1}), 64, ErrBadlyCompressed},
+		{"below threshold", compressedFrame(10, make([]byte, 10)), 64, ErrBadlyCompressed},
+		{"short data", compressedFrame(100, make([]byte, 99)), 64, ErrBadlyCompressed},
+		{"truncated", []byte{0x05, 0x00, 0x01}, 64, io.ErrUnexpectedEOF},
+	}
+	var sizeErr *FrameSizeError
+	for _, tt := range tests {
+		_, err := ReadFrame(bytes.NewReader(tt.frame), tt.threshold)
+		if tt.want == nil && !errors.As(err, &sizeErr) {
+			t.Errorf("%s: error = %v, want a FrameSizeError", tt.name, err)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := ReadFrame(bytes.NewReader(compressedFrame(MaxUncompressedSize, nil)), 64); errors.As(err, &sizeErr) {
+		t.Errorf("data length of exactly MaxUncompressedSize rejected as too large: %v", err)
+	}
+	if _, err := ReadLimitedFrame(bytes.NewReader([]byte{0x03, 1, 2, 3}), CompressionDisabled, 2); !errors.As(err, &sizeErr) || sizeErr.Max != 2 {
+		t.Errorf("ReadLimitedFrame error = %v, want a FrameSizeError with Max 2", err)
+	}
+}
@@ -5,7 +5,8 @@ import (
 	"github.com/NaymDev/mcgotocol/proto"
 )
 
-func MarshalPacket(p proto.Packet) ([]byte, error) {
+// EncodePacket returns the unframed packet ID and data of p.
+func EncodePacket(p proto.Packet) ([]byte, error) {
 	buf := &bytes.Buffer{}
 
 	if err := WriteVarInt(buf, VarInt(p.ID())); err != nil {
@@ -16,13 +17,19 @@ func MarshalPacket(p proto.Packet) ([]byte, error) {
 		return nil, err
 	}
 
-	packetData := buf.Bytes()
+	return buf.Bytes(), nil
+}
+
+func MarshalPacket(p proto.Packet) ([]byte, error) {
+	return MarshalCompressedPacket(p, CompressionDisabled)
+}
 
-	finalBuf := &bytes.Buffer{}
-	if err := WriteVarInt(finalBuf, VarInt(len(packetData))); err != nil {
+// MarshalCompressedPacket frames p using the given compression threshold.
+// A negative threshold produces the same output as MarshalPacket.
+func MarshalCompressedPacket(p proto.Packet, threshold int) ([]byte, error) {
+	packetData, err := EncodePacket(p)
+	if err != nil {
 		return nil, err
 	}
-
-	finalBuf.Write(packetData)
-	return finalBuf.Bytes(), nil
+	return AppendFrame(nil, packetData, threshold)
 }
@@ -5,18 +5,26 @@ import (
 	"io"
 )
 
+// MetadataType is the type of an entity metadata entry in the 1.8 protocol.
 type MetadataType byte
 
 const (
-	MetaByte MetadataType = iota
-	MetaVarInt
-	MetaFloat
-	MetaString
-	MetaSlot
-	MetaBool
-	MetaVector3F
+	MetaByte     MetadataType = iota // int8
+	MetaShort                        // int16
+	MetaInt                          // int32
+	MetaFloat                        // float32
+	MetaString                       // string
+	MetaSlot                         // ItemSlot
+	MetaPosition                     // [3]int32, not packed like Position
+	MetaRotation                     // [3]float32
 )
 
+// MetadataEnd terminates a metadata list.
+const MetadataEnd = 0x7F
+
+// MaxMetadataIndex is the highest index that fits the 5 bits of the header.
+const MaxMetadataIndex = 0x1F
+
 type EntityMetadata struct {
 	Index byte
 	Type  MetadataType
@@ -26,52 +34,47 @@ type EntityMetadata struct {
 func ReadMetadata(r io.Reader) ([]EntityMetadata, error) {
 	var result []EntityMetadata
 	for {
-		indexByte := make([]byte, 1)
-		if _, err := r.Read(indexByte); err != nil {
+		header, err := ReadUByte(r)
+		if err != nil {
 			return nil, err
 		}
-		if indexByte[0] == 0xFF {
+		if header == MetadataEnd {
 			break
 		}
-		metaTypeByte := make([]byte, 1)
-		if _, err := r.Read(metaTypeByte); err != nil {
-			return nil, err
-		}
 		entry := EntityMetadata{
-			Index: indexByte[0],
-			Type:  MetadataType(metaTypeByte[0]),
+			Index: header & MaxMetadataIndex,
+			Type:  MetadataType(header >> 5),
 		}
 
-		var err error
 		switch entry.Type {
 		case MetaByte:
 			entry.Value, err = ReadByte(r)
-		case MetaVarInt:
-			entry.Value, err = ReadVarInt(r)
+		case MetaShort:
+			entry.Value, err = ReadShort(r)
+		case MetaInt:
+			entry.Value, err = ReadInt(r)
 		case MetaFloat:
 			entry.Value, err = ReadFloat(r)
 		case MetaString:
 			entry.Value, err = ReadString(r)
 		case MetaSlot:
 			entry.Value, err = ReadSlot(r)
-		case MetaBool:
-			entry.Value, err = ReadBool(r)
-		case MetaVector3F:
-			x, err := ReadFloat(r)
-			if err != nil {
-				return nil, err
-			}
-			y, err := ReadFloat(r)
-			if err != nil {
-				return nil, err
-			}
-			z, err := ReadFloat(r)
-			if err != nil {
-				return nil, err
-			}
-			entry.Value = [3]float32{x, y, z}
-		default:
-			return nil, fmt.Errorf("unknown metadata type %d", entry.Type)
+		case MetaPosition:
+			var v [3]int32
+			for i := range v {
+				if v[i], err = ReadInt(r); err != nil {
+					return nil, err
+				}
+			}
+			entry.Value = v
+		case MetaRotation:
+			var v [3]float32
+			for i := range v {
+				if v[i], err = ReadFloat(r); err != nil {
+					return nil, err
+				}
+			}
+			entry.Value = v
 		}
 		if err != nil {
 			return nil, err
@@ -83,88 +86,96 @@ func ReadMetadata(r io.Reader) ([]EntityMetadata, error) {
 
 func WriteMetadata(w io.Writer, metadata []EntityMetadata) error {
 	for _, entry := range metadata {
-		if _, err := w.Write([]byte{entry.Index}); err != nil {
-			return err
+		if entry.Index > MaxMetadataIndex || entry.Type > MetaRotation {
+			return fmt.Errorf("invalid metadata entry: index %d, type %d", entry.Index, entry.Type)
+		}
+		header := byte(entry.Type)<<5 | entry.Index
+		if header == MetadataEnd {
+			// a float at index 31 would read as the end marker
+			return fmt.Errorf("invalid metadata entry: index %d, type %d", entry.Index, entry.Type)
 		}
-		if _, err := w.Write([]byte{byte(entry.Type)}); err != nil {
+		if err := WriteUByte(w, header); err != nil {
 			return err
 		}
 
 		switch entry.Type {
 		case MetaByte:
-			if v, ok := entry.Value.(int8); ok {
-				if err := WriteByte(w, v); err != nil {
-					return err
-				}
-			} else {
+			v, ok := entry.Value.(int8)
+			if !ok {
 				return fmt.Errorf("invalid value type for MetaByte")
 			}
+			if err := WriteByte(w, v); err != nil {
+				return err
+			}
 
-		case MetaVarInt:
-			if v, ok := entry.Value.(VarInt); ok {
-				if err := WriteVarInt(w, v); err != nil {
-					return err
-				}
-			} else {
-				return fmt.Errorf("invalid value type for MetaVarInt")
+		case MetaShort:
+			v, ok := entry.Value.(int16)
+			if !ok {
+				return fmt.Errorf("invalid value type for MetaShort")
+			}
+			if err := WriteShort(w, v); err != nil {
+				return err
+			}
+
+		case MetaInt:
+			v, ok := entry.Value.(int32)
+			if !ok {
+				return fmt.Errorf("invalid value type for MetaInt")
+			}
+			if err := WriteInt(w, v); err != nil {
+				return err
 			}
 
 		case MetaFloat:
-			if v, ok := entry.Value.(float32); ok {
-				if err := WriteFloat(w, v); err != nil {
-					return err
-				}
-			} else {
+			v, ok := entry.Value.(float32)
+			if !ok {
 				return fmt.Errorf("invalid value type for MetaFloat")
 			}
+			if err := WriteFloat(w, v); err != nil {
+				return err
+			}
 
 		case MetaString:
-			if v, ok := entry.Value.(string); ok {
-				if err := WriteString(w, v); err != nil {
-					return err
-				}
-			} else {
+			v, ok := entry.Value.(string)
+			if !ok {
 				return fmt.Errorf("invalid value type for MetaString")
 			}
+			if err := WriteString(w, v); err != nil {
+				return err
+			}
 
 		case MetaSlot:
-			if v, ok := entry.Value.(ItemSlot); ok {
-				if err := WriteSlot(w, v); err != nil {
-					return err
-				}
-			} else {
+			v, ok := entry.Value.(ItemSlot)
+			if !ok {
 				return fmt.Errorf("invalid value type for MetaSlot")
 			}
+			if err := WriteSlot(w, v); err != nil {
+				return err
+			}
 
-		case MetaBool:
-			if v, ok := entry.Value.(bool); ok {
-				if err := WriteBool(w, v); err != nil {
+		case MetaPosition:
+			v, ok := entry.Value.([3]int32)
+			if !ok {
+				return fmt.Errorf("invalid value type for MetaPosition")
+			}
+			for _, c := range v {
+				if err := WriteInt(w, c); err != nil {
 					return err
 				}
-			} else {
-				return fmt.Errorf("invalid value type for MetaBool")
 			}
 
-		case MetaVector3F:
-			if v, ok := entry.Value.([3]float32); ok {
-				if err := WriteFloat(w, v[0]); err != nil {
-					return err
-				}
-				if err := WriteFloat(w, v[1]); err != nil {
-					return err
-				}
-				if err := WriteFloat(w, v[2]); err != nil {
+		case MetaRotation:
+			v, ok := entry.Value.([3]float32)
+			if !ok {
+				return fmt.Errorf("invalid value type for MetaRotation")
+			}
+			for _, c := range v {
+				if err := WriteFloat(w, c); err != nil {
 					return err
 				}
-			} else {
-				return fmt.Errorf("invalid value type for MetaVector3F")
 			}
-
-		default:
-			return fmt.Errorf("unknown metadata type %d", entry.Type)
 		}
 	}
 
-	_, err := w.Write([]byte{0xFF})
-	return err
+	return WriteUByte(w, MetadataEnd)
 }
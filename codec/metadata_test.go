@@ -0,0 +1,99 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// the metadata of a freshly spawned player as sent by a 1.8 server
+var playerMetadata = []byte{
+	0x00, 0x00, // flags
+	0x21, 0x01, 0x2c, // air 300
+	0x82, 0x00, // custom name ""
+	0x03, 0x00, // custom name visible
+	0x04, 0x00, // silent
+	0x66, 0x41, 0xa0, 0x00, 0x00, // health 20
+	0x47, 0x00, 0x00, 0x00, 0x00, // potion color
+	0x08, 0x00, // potion ambient
+	0x09, 0x00, // arrows
+	0x0a, 0x7f, // skin parts
+	0x10, 0x00, // cape hidden
+	0x71, 0x00, 0x00, 0x00, 0x00, // absorption
+	0x52, 0x00, 0x00, 0x00, 0x00, // score
+	0x7f,
+}
+
+func TestMetadataVectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		wire     []byte
+		metadata []EntityMetadata
+	}{
+		{"empty", []byte{0x7f}, nil},
+		{"player", playerMetadata, []EntityMetadata{
+			{0, MetaByte, int8(0)},
+			{1, MetaShort, int16(300)},
+			{2, MetaString, ""},
+			{3, MetaByte, int8(0)},
+			{4, MetaByte, int8(0)},
+			{6, MetaFloat, float32(20)},
+			{7, MetaInt, int32(0)},
+			{8, MetaByte, int8(0)},
+			{9, MetaByte, int8(0)},
+			{10, MetaByte, int8(0x7f)},
+			{16, MetaByte, int8(0)},
+			{17, MetaFloat, float32(0)},
+			{18, MetaInt, int32(0)},
+		}},
+		{"dropped stone", []byte{0x00, 0x00, 0x21, 0x01, 0x2c, 0xaa, 0x00, 0x01, 0x01, 0x00, 0x00, 0x00, 0x7f}, []EntityMetadata{
+			{0, MetaByte, int8(0)},
+			{1, MetaShort, int16(300)},
+			{10, MetaSlot, ItemSlot{ItemID: 1, Count: 1}},
+		}},
+		{"armor stand head", []byte{
+			0xeb, 0x41, 0xf0, 0x00, 0x00, 0xc1, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x7f,
+		}, []EntityMetadata{
+			{11, MetaRotation, [3]float32{30, -10, 0}},
+		}},
+		{"item frame position", []byte{
+			0xc8, 0x00, 0x00, 0x00, 0x01, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x40,
+			0x7f,
+		}, []EntityMetadata{
+			{8, MetaPosition, [3]int32{1, -1, 64}},
+		}},
+	}
+	for _, tt := range tests {
+		for _, r := range []io.Reader{bytes.NewReader(tt.wire), plainReader{bytes.NewReader(tt.wire)}} {
+			got, err := ReadMetadata(r)
+			if err != nil || !reflect.DeepEqual(got, tt.metadata) {
+				t.Errorf("%s: ReadMetadata = %+v, %v", tt.name, got, err)
+			}
+		}
+		buf := &bytes.Buffer{}
+		if err := WriteMetadata(buf, tt.metadata); err != nil || !bytes.Equal(buf.Bytes(), tt.wire) {
+			t.Errorf("%s: WriteMetadata = % x, %v", tt.name, buf.Bytes(), err)
+		}
+	}
+}
+
+func TestMetadataInvalid(t *testing.T) {
+	for _, entry := range []EntityMetadata{
+		{31, MetaFloat, float32(0)}, // would be the end marker
+		{32, MetaByte, int8(0)},
+		{0, MetaRotation + 1, nil},
+		{0, MetaByte, 0},
+		{0, MetaSlot, &ItemSlot{}},
+	} {
+		if err := WriteMetadata(io.Discard, []EntityMetadata{entry}); err == nil {
+			t.Errorf("WriteMetadata(%+v) succeeded", entry)
+		}
+	}
+
+	if _, err := ReadMetadata(bytes.NewReader([]byte{0x00, 0x00})); !errors.Is(err, io.EOF) {
+		t.Errorf("ReadMetadata without end = %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package codec
+
+import (
+	"github.com/NaymDev/mcgotocol/nbt"
+	"io"
+)
+
+// ReadNBT reads the named root compound used by 1.8 packets. A single
+// TAG_End byte stands for "no NBT" and yields a nil compound.
+func ReadNBT(r io.Reader) (nbt.Compound, error) {
+	_, tag, err := nbt.NewDecoder(r).ReadRoot()
+	if err != nil || tag == nil {
+		return nil, err
+	}
+	c, ok := tag.(nbt.Compound)
+	if !ok {
+		return nil, nbt.ErrInvalidTagType
+	}
+	return c, nil
+}
+
+// WriteNBT writes c as root compound with an empty name, or a single
+// TAG_End for a nil compound.
+func WriteNBT(w io.Writer, c nbt.Compound) error {
+	if c == nil {
+		return nbt.NewEncoder(w).WriteRoot("", nil)
+	}
+	return nbt.NewEncoder(w).WriteRoot("", c)
+}
+
+// ReadNBTValue reads a root compound into v, see nbt.Unmarshal. v is left
+// untouched if the packet carries no NBT.
+func ReadNBTValue(r io.Reader, v any) error {
+	return nbt.NewDecoder(r).Decode(v)
+}
+
+// WriteNBTValue marshals v as root compound, see nbt.Marshal.
+func WriteNBTValue(w io.Writer, v any) error {
+	return nbt.NewEncoder(w).Encode(v)
+}
@@ -1,7 +1,6 @@
 package codec
 
 import (
-	"encoding/binary"
 	"io"
 )
 
@@ -11,29 +10,57 @@ func WritePosition(w io.Writer, x, y, z int32) error {
 	uz := uint64(z & 0x3FFFFFF)
 
 	val := (ux << 38) | (uy << 26) | uz
-	return binary.Write(w, binary.BigEndian, val)
+	return WriteLong(w, int64(val))
+}
+
+// AppendPosition appends the packed form of p to dst.
+func AppendPosition(dst []byte, p Position) []byte {
+	val := uint64(p.X&0x3FFFFFF)<<38 | uint64(p.Y&0xFFF)<<26 | uint64(p.Z&0x3FFFFFF)
+	return AppendLong(dst, int64(val))
 }
 
 func ReadPosition(r io.Reader) (x, y, z int32, err error) {
-	var val uint64
-	if err = binary.Read(r, binary.BigEndian, &val); err != nil {
+	raw, err := ReadLong(r)
+	if err != nil {
 		return
 	}
+	p := unpackPosition(uint64(raw))
+	return p.X, p.Y, p.Z, nil
+}
 
-	x = int32(val >> 38)
+func unpackPosition(val uint64) Position {
+	x := int32(val >> 38)
 	if x >= 1<<25 {
 		x -= 1 << 26
 	}
 
-	y = int32((val >> 26) & 0xFFF)
+	y := int32((val >> 26) & 0xFFF)
 	if y >= 1<<11 {
 		y -= 1 << 12
 	}
 
-	z = int32(val & 0x3FFFFFF)
+	z := int32(val & 0x3FFFFFF)
 	if z >= 1<<25 {
 		z -= 1 << 26
 	}
 
-	return
+	return Position{X: x, Y: y, Z: z}
+}
+
+// Position is a block position packed into a single long on the wire.
+type Position struct {
+	X int32
+	Y int32
+	Z int32
+}
+
+func WritePos(w io.Writer, p Position) error {
+	return WritePosition(w, p.X, p.Y, p.Z)
+}
+
+func ReadPos(r io.Reader) (Position, error) {
+	var p Position
+	var err error
+	p.X, p.Y, p.Z, err = ReadPosition(r)
+	return p, err
 }
@@ -1,61 +1,100 @@
 package codec
 
-import "io"
+import (
+	"bytes"
+	"github.com/NaymDev/mcgotocol/nbt"
+	"io"
+)
 
+// EmptyItemID marks an empty slot on the wire.
+const EmptyItemID = -1
+
+// ItemSlot is a 1.8 slot: short item ID, byte count, short damage and a
+// named root compound (or a single 0x00 byte for no NBT).
+//
+// ReadSlot keeps the NBT as the exact bytes received in NBTData and leaves NBT
+// nil, so writing a slot that was read reproduces the original bytes even
+// though vanilla does not sort compound keys. Use ParseNBT to inspect it and
+// SetNBT to replace it. WriteSlot writes NBT instead of NBTData when it is
+// non-nil.
 type ItemSlot struct {
-	ItemID  VarInt
+	ItemID  int16
 	Count   int8
+	Damage  int16
+	NBT     nbt.Compound
 	NBTData []byte
 }
 
+var EmptySlot = ItemSlot{ItemID: EmptyItemID}
+
+func (s ItemSlot) IsEmpty() bool {
+	return s.ItemID == EmptyItemID
+}
+
+// ParseNBT returns NBT if set, otherwise the parsed NBTData. It returns nil
+// if the item has no NBT.
+func (s ItemSlot) ParseNBT() (nbt.Compound, error) {
+	if s.NBT != nil || len(s.NBTData) == 0 {
+		return s.NBT, nil
+	}
+	return ReadNBT(bytes.NewReader(s.NBTData))
+}
+
+// SetNBT replaces the NBT of the item and drops the raw NBTData.
+func (s *ItemSlot) SetNBT(c nbt.Compound) {
+	s.NBT = c
+	s.NBTData = nil
+}
+
 func ReadSlot(r io.Reader) (ItemSlot, error) {
 	var slot ItemSlot
-	id, err := ReadVarInt(r)
+	id, err := ReadShort(r)
 	if err != nil {
 		return slot, err
 	}
 	slot.ItemID = id
 
-	if id < 0 {
+	if id == EmptyItemID {
 		return slot, nil
 	}
 
-	count, err := ReadByte(r)
-	if err != nil {
+	if slot.Count, err = ReadByte(r); err != nil {
+		return slot, err
+	}
+	if slot.Damage, err = ReadShort(r); err != nil {
 		return slot, err
 	}
-	slot.Count = count
 
-	nbt, err := ReadByteArray(r)
-	if err != nil {
+	// the NBT has no length prefix, decode it to find its end
+	raw := &bytes.Buffer{}
+	if _, err := ReadNBT(io.TeeReader(r, raw)); err != nil {
 		return slot, err
 	}
-	if len(nbt) == 0xFF {
-		slot.NBTData = nil
-	} else {
-		slot.NBTData = nbt
+	if raw.Len() > 1 {
+		slot.NBTData = raw.Bytes()
 	}
 	return slot, nil
 }
 
 func WriteSlot(w io.Writer, slot ItemSlot) error {
-	if err := WriteVarInt(w, slot.ItemID); err != nil {
+	if err := WriteShort(w, slot.ItemID); err != nil {
 		return err
 	}
-	if slot.ItemID < 0 {
+	if slot.ItemID == EmptyItemID {
 		return nil
 	}
 	if err := WriteByte(w, slot.Count); err != nil {
 		return err
 	}
-	if slot.NBTData == nil {
-		if err := WriteVarInt(w, -1); err != nil {
-			return err
-		}
-	} else {
-		if err := WriteByteArray(w, slot.NBTData); err != nil {
-			return err
-		}
+	if err := WriteShort(w, slot.Damage); err != nil {
+		return err
+	}
+	switch {
+	case slot.NBT != nil:
+		return WriteNBT(w, slot.NBT)
+	case len(slot.NBTData) > 0:
+		_, err := w.Write(slot.NBTData)
+		return err
 	}
-	return nil
+	return WriteNBT(w, nil)
 }
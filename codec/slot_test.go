@@ -0,0 +1,117 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NaymDev/mcgotocol/nbt"
+)
+
+// a diamond sword with Sharpness V as sent by a 1.8 server
+var swordSlot = []byte{
+	0x01, 0x14, // id 276
+	0x01,       // count
+	0x00, 0x00, // damage
+	0x0a, 0x00, 0x00, // root compound ""
+	0x09, 0x00, 0x04, 'e', 'n', 'c', 'h', 0x0a, 0x00, 0x00, 0x00, 0x01,
+	0x02, 0x00, 0x02, 'i', 'd', 0x00, 0x10,
+	0x02, 0x00, 0x03, 'l', 'v', 'l', 0x00, 0x05,
+	0x00, // end of the enchantment
+	0x00, // end of the root
+}
+
+func TestSlotVectors(t *testing.T) {
+	tests := []struct {
+		name string
+		wire []byte
+		slot ItemSlot
+	}{
+		{"empty", []byte{0xff, 0xff}, EmptySlot},
+		{"stone", []byte{0x00, 0x01, 0x40, 0x00, 0x00, 0x00}, ItemSlot{ItemID: 1, Count: 64}},
+	}
+	for _, tt := range tests {
+		got, err := ReadSlot(bytes.NewReader(tt.wire))
+		if err != nil || got.ItemID != tt.slot.ItemID || got.Count != tt.slot.Count || got.NBT != nil || got.NBTData != nil {
+			t.Errorf("%s: ReadSlot = %+v, %v", tt.name, got, err)
+		}
+		buf := &bytes.Buffer{}
+		if err := WriteSlot(buf, tt.slot); err != nil || !bytes.Equal(buf.Bytes(), tt.wire) {
+			t.Errorf("%s: WriteSlot = % x, %v", tt.name, buf.Bytes(), err)
+		}
+	}
+}
+
+// a renamed, enchanted sword; vanilla writes compound keys in hash order, so
+// ench comes before display and Name before Lore
+var renamedSlot = []byte{
+	0x01, 0x14, // id 276
+	0x01,       // count
+	0x00, 0x00, // damage
+	0x0a, 0x00, 0x00, // root compound ""
+	0x09, 0x00, 0x04, 'e', 'n', 'c', 'h', 0x0a, 0x00, 0x00, 0x00, 0x01,
+	0x02, 0x00, 0x03, 'l', 'v', 'l', 0x00, 0x05,
+	0x02, 0x00, 0x02, 'i', 'd', 0x00, 0x10,
+	0x00, // end of the enchantment
+	0x0a, 0x00, 0x07, 'd', 'i', 's', 'p', 'l', 'a', 'y',
+	0x08, 0x00, 0x04, 'N', 'a', 'm', 'e', 0x00, 0x03, 'F', 'o', 'o',
+	0x09, 0x00, 0x04, 'L', 'o', 'r', 'e', 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0x03, 'B', 'a', 'r',
+	0x00, // end of display
+	0x00, // end of the root
+}
+
+func TestSlotNBT(t *testing.T) {
+	for _, wire := range [][]byte{swordSlot, renamedSlot} {
+		slot, err := ReadSlot(bytes.NewReader(wire))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slot.ItemID != 276 || slot.Count != 1 || slot.NBT != nil {
+			t.Fatalf("ReadSlot = %+v", slot)
+		}
+		if !bytes.Equal(slot.NBTData, wire[5:]) {
+			t.Errorf("NBTData = % x", slot.NBTData)
+		}
+		buf := &bytes.Buffer{}
+		if err := WriteSlot(buf, slot); err != nil || !bytes.Equal(buf.Bytes(), wire) {
+			t.Errorf("WriteSlot = % x, %v", buf.Bytes(), err)
+		}
+	}
+
+	slot, err := ReadSlot(bytes.NewReader(renamedSlot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := slot.ParseNBT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ench := tag.List("ench")
+	if ench == nil || len(ench.Elems) != 1 {
+		t.Fatalf("NBT = %v, want one enchantment", tag)
+	}
+	if lvl, _ := ench.Elems[0].(nbt.Compound).Int("lvl"); lvl != 5 {
+		t.Errorf("lvl = %d, want 5", lvl)
+	}
+	if name, _ := tag.Compound("display").String("Name"); name != "Foo" {
+		t.Errorf("Name = %q, want Foo", name)
+	}
+
+	// SetNBT replaces the received bytes
+	ench.Elems[0].(nbt.Compound)["lvl"] = nbt.Short(1)
+	slot.SetNBT(tag)
+	buf := &bytes.Buffer{}
+	if err := WriteSlot(buf, slot); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadSlot(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err = got.ParseNBT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lvl, _ := tag.List("ench").Elems[0].(nbt.Compound).Int("lvl"); lvl != 1 {
+		t.Errorf("modified NBT was not written: % x", buf.Bytes())
+	}
+}
@@ -0,0 +1,807 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/NaymDev/mcgotocol/nbt"
+	"github.com/google/uuid"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ============================
+//  Struct-tag driven encoding
+// ============================
+//
+// EncodeStruct and DecodeStruct (de)serialize the exported fields of a struct
+// in declaration order. The wire type of a field follows from its Go type
+// (VarInt, Angle, Chat, Position, uuid.UUID, ItemSlot, []EntityMetadata,
+// nbt.Compound, bool, int8, uint8, int16, uint16, int32, int64, float32,
+// float64, string, []byte as VarInt prefixed bytes, other slices as VarInt
+// prefixed arrays, nested structs and types with Encode/Decode methods) and
+// can be overridden with an `mc` tag:
+//
+//	EntityID int32        `mc:"varint"`
+//	Yaw      uint8        `mc:"angle"`
+//	Data     []byte       `mc:"rest"`           // remaining bytes, no prefix
+//	Records  []int32      `mc:"array,elem=varint"`
+//	Name     *codec.Chat  `mc:"optional=HasName"` // only if bool field HasName is true
+//	Cache    int          `mc:"-"`
+//
+// The plan for each type is built once and cached. Types may refer to
+// themselves, e.g. through a slice of their own type.
+//
+// To stay close to handwritten Encode/Decode methods, plans address fields by
+// their offset instead of creating a reflect.Value per field. EncodeStruct
+// appends the whole struct to one buffer and writes it at once, and
+// DecodeStruct reads runs of fixed size fields with a single Read. The
+// benchmarks in struct_test.go compare both against handwritten methods for a
+// struct shaped like ClientJoinGame: encoding takes about 1.5 times as long
+// and decoding about twice as long, without extra allocations. Packets on hot
+// paths can still implement Encode/Decode by hand.
+
+// MaxArrayLength caps the element count of arrays decoded by DecodeStruct, so
+// that a forged count cannot make it allocate unbounded memory whatever the
+// reader.
+const MaxArrayLength = 1 << 16
+
+var ErrUnsupportedField = errors.New("unsupported field type")
+
+// fieldCodec converts the value at p. Fields with a fixed wire size (size > 0)
+// also have get, which decodes them from exactly size bytes.
+type fieldCodec struct {
+	size   int
+	append func(dst []byte, p unsafe.Pointer) ([]byte, error)
+	decode func(r io.Reader, p unsafe.Pointer) error
+	get    func(b []byte, p unsafe.Pointer)
+}
+
+type fieldPlan struct {
+	name     string
+	offset   uintptr
+	optional bool
+	guard    uintptr // offset of the bool field guarding an optional field
+	codec    fieldCodec
+}
+
+func (f *fieldPlan) present(base unsafe.Pointer) bool {
+	return !f.optional || *(*bool)(unsafe.Add(base, f.guard))
+}
+
+// decodeStep is either a run of fixed size fields read at once (size > 0) or
+// a single field.
+type decodeStep struct {
+	size   int
+	fields []fieldPlan
+}
+
+type structPlan struct {
+	fields []fieldPlan
+	steps  []decodeStep
+}
+
+var (
+	// plans is replaced, never modified, when plans are added, so lookups
+	// need no lock.
+	plans atomic.Pointer[map[reflect.Type]*structPlan]
+
+	// planMu serializes building plans. building holds the plans under
+	// construction so that self-referential types resolve to them instead
+	// of recursing forever; they are only published once complete.
+	planMu   sync.Mutex
+	building = map[reflect.Type]*structPlan{}
+
+	// encodeBuffers hold the output of EncodeStruct until it is written.
+	encodeBuffers = sync.Pool{New: func() any { return new([]byte) }}
+	// fixedBuffers are used to read fixed size fields and runs of them.
+	fixedBuffers = sync.Pool{New: func() any { return new([]byte) }}
+)
+
+// EncodeStruct writes the fields of the struct v (or pointer to it).
+func EncodeStruct(w io.Writer, v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	plan, err := planFor(rv.Type())
+	if err != nil {
+		return err
+	}
+	if !rv.CanAddr() {
+		// fields are read in place, which needs an addressable struct
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		rv = p.Elem()
+	}
+
+	buf := encodeBuffers.Get().(*[]byte)
+	defer encodeBuffers.Put(buf)
+	b, err := plan.append((*buf)[:0], rv.Addr().UnsafePointer())
+	if cap(b) <= 1<<16 {
+		*buf = b
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// DecodeStruct reads the fields of the struct pointed to by v.
+func DecodeStruct(r io.Reader, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: DecodeStruct needs a non-nil pointer, got %T", ErrUnsupportedField, v)
+	}
+	plan, err := planFor(rv.Type().Elem())
+	if err != nil {
+		return err
+	}
+	return plan.decode(r, rv.UnsafePointer())
+}
+
+func (p *structPlan) append(dst []byte, base unsafe.Pointer) ([]byte, error) {
+	for i := range p.fields {
+		f := &p.fields[i]
+		if !f.present(base) {
+			continue
+		}
+		var err error
+		if dst, err = f.codec.append(dst, unsafe.Add(base, f.offset)); err != nil {
+			return dst, fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	return dst, nil
+}
+
+func (p *structPlan) decode(r io.Reader, base unsafe.Pointer) error {
+	for i := range p.steps {
+		step := &p.steps[i]
+		if step.size > 0 {
+			if err := decodeRun(r, base, step); err != nil {
+				return err
+			}
+			continue
+		}
+		f := &step.fields[0]
+		if !f.present(base) {
+			continue
+		}
+		if err := f.codec.decode(r, unsafe.Add(base, f.offset)); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+func decodeRun(r io.Reader, base unsafe.Pointer, step *decodeStep) error {
+	scratch := fixedBuffers.Get().(*[]byte)
+	defer fixedBuffers.Put(scratch)
+	buf := growScratch(scratch, step.size)
+	if n, err := io.ReadFull(r, buf); err != nil {
+		// name the field that could not be read completely
+		for i := range step.fields {
+			f := &step.fields[i]
+			if n < f.codec.size {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+			n -= f.codec.size
+		}
+		return err
+	}
+	for i := range step.fields {
+		f := &step.fields[i]
+		f.codec.get(buf[:f.codec.size], unsafe.Add(base, f.offset))
+		buf = buf[f.codec.size:]
+	}
+	return nil
+}
+
+func growScratch(buf *[]byte, n int) []byte {
+	if cap(*buf) < n {
+		*buf = make([]byte, n)
+	}
+	return (*buf)[:n]
+}
+
+func planFor(t reflect.Type) (*structPlan, error) {
+	if cached, ok := lookupPlan(t); ok {
+		return cached, nil
+	}
+	planMu.Lock()
+	defer planMu.Unlock()
+	defer clear(building)
+
+	plan, err := buildPlan(t)
+	if err != nil || len(building) == 0 {
+		return plan, err
+	}
+	next := make(map[reflect.Type]*structPlan, len(building))
+	if cur := plans.Load(); cur != nil {
+		for typ, p := range *cur {
+			next[typ] = p
+		}
+	}
+	for typ, p := range building {
+		next[typ] = p
+	}
+	plans.Store(&next)
+	return plan, nil
+}
+
+func lookupPlan(t reflect.Type) (*structPlan, bool) {
+	if cur := plans.Load(); cur != nil {
+		plan, ok := (*cur)[t]
+		return plan, ok
+	}
+	return nil, false
+}
+
+// buildPlan must be called with planMu held.
+func buildPlan(t reflect.Type) (*structPlan, error) {
+	if cached, ok := lookupPlan(t); ok {
+		return cached, nil
+	}
+	if plan, ok := building[t]; ok {
+		return plan, nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is not a struct", ErrUnsupportedField, t)
+	}
+
+	plan := &structPlan{}
+	building[t] = plan
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("mc")
+		if tag == "-" {
+			continue
+		}
+
+		var wire, elemWire string
+		f := fieldPlan{name: sf.Name, offset: sf.Offset}
+		for _, opt := range strings.Split(tag, ",") {
+			key, value, hasValue := strings.Cut(opt, "=")
+			switch {
+			case opt == "":
+			case !hasValue && wire == "":
+				wire = key
+			case key == "elem":
+				elemWire = value
+			case key == "optional":
+				guard, ok := t.FieldByName(value)
+				if !ok || guard.Type.Kind() != reflect.Bool || len(guard.Index) != 1 || guard.Index[0] >= i {
+					return nil, fmt.Errorf("%w: %s.%s: optional needs a preceding bool field, got %q", ErrUnsupportedField, t, sf.Name, value)
+				}
+				f.optional, f.guard = true, guard.Offset
+			default:
+				return nil, fmt.Errorf("%w: %s.%s: unknown option %q", ErrUnsupportedField, t, sf.Name, opt)
+			}
+		}
+
+		c, err := codecFor(sf.Type, wire, elemWire)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, sf.Name, err)
+		}
+		f.codec = c
+		plan.fields = append(plan.fields, f)
+	}
+
+	// group consecutive fixed size fields so that decode reads them at once
+	for _, f := range plan.fields {
+		n := len(plan.steps)
+		fixed := f.codec.size > 0 && !f.optional
+		if fixed && n > 0 && plan.steps[n-1].size > 0 {
+			plan.steps[n-1].size += f.codec.size
+			plan.steps[n-1].fields = append(plan.steps[n-1].fields, f)
+			continue
+		}
+		step := decodeStep{fields: []fieldPlan{f}}
+		if fixed {
+			step.size = f.codec.size
+		}
+		plan.steps = append(plan.steps, step)
+	}
+	return plan, nil
+}
+
+// selfCoder is implemented (with pointer receivers) by types that encode
+// themselves, e.g. packet.Property.
+type selfCoder interface {
+	Encode(io.Writer) error
+	Decode(io.Reader) error
+}
+
+var (
+	selfCoderType = reflect.TypeOf((*selfCoder)(nil)).Elem()
+	varIntType    = reflect.TypeOf(VarInt(0))
+	varLongType   = reflect.TypeOf(VarLong(0))
+	angleType     = reflect.TypeOf(Angle(0))
+	chatType      = reflect.TypeOf(Chat(""))
+	positionType  = reflect.TypeOf(Position{})
+	uuidType      = reflect.TypeOf(uuid.UUID{})
+	slotType      = reflect.TypeOf(ItemSlot{})
+	metadataType  = reflect.TypeOf([]EntityMetadata(nil))
+	compoundType  = reflect.TypeOf(nbt.Compound(nil))
+)
+
+func defaultWire(t reflect.Type) string {
+	switch t {
+	case varIntType:
+		return "varint"
+	case varLongType:
+		return "varlong"
+	case angleType:
+		return "angle"
+	case chatType:
+		return "chat"
+	case positionType:
+		return "position"
+	case uuidType:
+		return "uuid"
+	case slotType:
+		return "slot"
+	case metadataType:
+		return "metadata"
+	case compoundType:
+		return "nbt"
+	}
+	if reflect.PointerTo(t).Implements(selfCoderType) {
+		return "self"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int8:
+		return "byte"
+	case reflect.Uint8:
+		return "ubyte"
+	case reflect.Int16:
+		return "short"
+	case reflect.Uint16:
+		return "ushort"
+	case reflect.Int32:
+		return "int"
+	case reflect.Int64:
+		return "long"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+		return "array"
+	case reflect.Struct:
+		return "struct"
+	}
+	return ""
+}
+
+func codecFor(t reflect.Type, wire, elemWire string) (fieldCodec, error) {
+	if t.Kind() == reflect.Pointer {
+		// pointers take the wire type of their element, e.g. *Chat
+		elem, err := codecFor(t.Elem(), wire, elemWire)
+		if err != nil {
+			return fieldCodec{}, err
+		}
+		return pointerCodec(t, elem), nil
+	}
+	if wire == "" {
+		wire = defaultWire(t)
+	}
+
+	switch wire {
+	case "bool":
+		if t.Kind() != reflect.Bool {
+			break
+		}
+		return fixedCodec(1,
+			func(dst []byte, p unsafe.Pointer) []byte { return AppendBool(dst, *(*bool)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*bool)(p) = b[0] != 0 }), nil
+	case "byte":
+		return intCodec(t, 1,
+			func(dst []byte, n int64) []byte { return append(dst, byte(n)) },
+			func(b []byte) int64 { return int64(int8(b[0])) })
+	case "ubyte", "angle":
+		return intCodec(t, 1,
+			func(dst []byte, n int64) []byte { return append(dst, byte(n)) },
+			func(b []byte) int64 { return int64(b[0]) })
+	case "short":
+		return intCodec(t, 2,
+			func(dst []byte, n int64) []byte { return AppendShort(dst, int16(n)) },
+			func(b []byte) int64 { return int64(int16(binary.BigEndian.Uint16(b))) })
+	case "ushort":
+		return intCodec(t, 2,
+			func(dst []byte, n int64) []byte { return AppendUShort(dst, uint16(n)) },
+			func(b []byte) int64 { return int64(binary.BigEndian.Uint16(b)) })
+	case "int":
+		return intCodec(t, 4,
+			func(dst []byte, n int64) []byte { return AppendInt(dst, int32(n)) },
+			func(b []byte) int64 { return int64(int32(binary.BigEndian.Uint32(b))) })
+	case "long":
+		return intCodec(t, 8,
+			func(dst []byte, n int64) []byte { return AppendLong(dst, n) },
+			func(b []byte) int64 { return int64(binary.BigEndian.Uint64(b)) })
+	case "varint":
+		return varCodec(t,
+			func(dst []byte, n int64) []byte { return AppendVarInt(dst, VarInt(n)) },
+			func(r io.Reader) (int64, error) {
+				n, err := ReadVarInt(r)
+				return int64(n), err
+			})
+	case "varlong":
+		return varCodec(t,
+			func(dst []byte, n int64) []byte { return AppendVarLong(dst, VarLong(n)) },
+			func(r io.Reader) (int64, error) {
+				n, err := ReadVarLong(r)
+				return int64(n), err
+			})
+	case "float", "double":
+		load, store := floatAccess(t)
+		if load == nil {
+			break
+		}
+		if wire == "float" {
+			return fixedCodec(4,
+				func(dst []byte, p unsafe.Pointer) []byte { return AppendFloat(dst, float32(load(p))) },
+				func(b []byte, p unsafe.Pointer) {
+					store(p, float64(math.Float32frombits(binary.BigEndian.Uint32(b))))
+				}), nil
+		}
+		return fixedCodec(8,
+			func(dst []byte, p unsafe.Pointer) []byte { return AppendDouble(dst, load(p)) },
+			func(b []byte, p unsafe.Pointer) { store(p, math.Float64frombits(binary.BigEndian.Uint64(b))) }), nil
+	case "string", "chat":
+		if t.Kind() != reflect.String {
+			break
+		}
+		// named string types share the layout of string
+		if wire == "chat" {
+			return fieldCodec{
+				append: func(dst []byte, p unsafe.Pointer) ([]byte, error) { return AppendChat(dst, *(*Chat)(p)) },
+				decode: func(r io.Reader, p unsafe.Pointer) error {
+					c, err := ReadChat(r)
+					*(*Chat)(p) = c
+					return err
+				},
+			}, nil
+		}
+		return fieldCodec{
+			append: func(dst []byte, p unsafe.Pointer) ([]byte, error) { return AppendString(dst, *(*string)(p)), nil },
+			decode: func(r io.Reader, p unsafe.Pointer) error {
+				s, err := ReadString(r)
+				*(*string)(p) = s
+				return err
+			},
+		}, nil
+	case "bytes", "rest":
+		if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		if wire == "rest" {
+			return fieldCodec{
+				append: func(dst []byte, p unsafe.Pointer) ([]byte, error) { return append(dst, *(*[]byte)(p)...), nil },
+				decode: func(r io.Reader, p unsafe.Pointer) error {
+					b, err := io.ReadAll(r)
+					*(*[]byte)(p) = b
+					return err
+				},
+			}, nil
+		}
+		return fieldCodec{
+			append: func(dst []byte, p unsafe.Pointer) ([]byte, error) { return AppendByteArray(dst, *(*[]byte)(p)), nil },
+			decode: func(r io.Reader, p unsafe.Pointer) error {
+				b, err := ReadByteArray(r)
+				*(*[]byte)(p) = b
+				return err
+			},
+		}, nil
+	case "array":
+		if t.Kind() != reflect.Slice {
+			break
+		}
+		elem, err := codecFor(t.Elem(), elemWire, "")
+		if err != nil {
+			return fieldCodec{}, err
+		}
+		return arrayCodec(t, elem), nil
+	case "position", "uuid", "slot", "metadata", "nbt":
+		if t != defaultTypes[wire] {
+			break
+		}
+		return valueCodec(wire), nil
+	case "self":
+		if !reflect.PointerTo(t).Implements(selfCoderType) {
+			break
+		}
+		return fieldCodec{
+			append: func(dst []byte, p unsafe.Pointer) ([]byte, error) {
+				return appendWith(dst, reflect.NewAt(t, p).Interface().(selfCoder).Encode)
+			},
+			decode: func(r io.Reader, p unsafe.Pointer) error {
+				return reflect.NewAt(t, p).Interface().(selfCoder).Decode(r)
+			},
+		}, nil
+	case "struct":
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		// plan may still be under construction if t refers to itself
+		plan, err := buildPlan(t)
+		if err != nil {
+			return fieldCodec{}, err
+		}
+		return fieldCodec{append: plan.append, decode: plan.decode}, nil
+	}
+	return fieldCodec{}, fmt.Errorf("%w: cannot encode %s as %q", ErrUnsupportedField, t, wire)
+}
+
+var defaultTypes = map[string]reflect.Type{
+	"position": positionType,
+	"uuid":     uuidType,
+	"slot":     slotType,
+	"metadata": metadataType,
+	"nbt":      compoundType,
+}
+
+// fixedCodec builds the codec of a wire type that is always size bytes long.
+func fixedCodec(size int, put func(dst []byte, p unsafe.Pointer) []byte, get func(b []byte, p unsafe.Pointer)) fieldCodec {
+	return fieldCodec{
+		size:   size,
+		append: func(dst []byte, p unsafe.Pointer) ([]byte, error) { return put(dst, p), nil },
+		decode: func(r io.Reader, p unsafe.Pointer) error {
+			scratch := fixedBuffers.Get().(*[]byte)
+			defer fixedBuffers.Put(scratch)
+			b := growScratch(scratch, size)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return err
+			}
+			get(b, p)
+			return nil
+		},
+		get: get,
+	}
+}
+
+// intAccess returns functions loading and storing an integer of type t as
+// int64; unsigned values are stored in two's complement.
+func intAccess(t reflect.Type) (load func(unsafe.Pointer) int64, store func(unsafe.Pointer, int64)) {
+	switch t.Kind() {
+	case reflect.Int8:
+		return func(p unsafe.Pointer) int64 { return int64(*(*int8)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*int8)(p) = int8(n) }
+	case reflect.Uint8:
+		return func(p unsafe.Pointer) int64 { return int64(*(*uint8)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*uint8)(p) = uint8(n) }
+	case reflect.Int16:
+		return func(p unsafe.Pointer) int64 { return int64(*(*int16)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*int16)(p) = int16(n) }
+	case reflect.Uint16:
+		return func(p unsafe.Pointer) int64 { return int64(*(*uint16)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*uint16)(p) = uint16(n) }
+	case reflect.Int32:
+		return func(p unsafe.Pointer) int64 { return int64(*(*int32)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*int32)(p) = int32(n) }
+	case reflect.Uint32:
+		return func(p unsafe.Pointer) int64 { return int64(*(*uint32)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*uint32)(p) = uint32(n) }
+	case reflect.Int64:
+		return func(p unsafe.Pointer) int64 { return *(*int64)(p) },
+			func(p unsafe.Pointer, n int64) { *(*int64)(p) = n }
+	case reflect.Uint64:
+		return func(p unsafe.Pointer) int64 { return int64(*(*uint64)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*uint64)(p) = uint64(n) }
+	case reflect.Int:
+		return func(p unsafe.Pointer) int64 { return int64(*(*int)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*int)(p) = int(n) }
+	case reflect.Uint:
+		return func(p unsafe.Pointer) int64 { return int64(*(*uint)(p)) },
+			func(p unsafe.Pointer, n int64) { *(*uint)(p) = uint(n) }
+	}
+	return nil, nil
+}
+
+func floatAccess(t reflect.Type) (load func(unsafe.Pointer) float64, store func(unsafe.Pointer, float64)) {
+	switch t.Kind() {
+	case reflect.Float32:
+		return func(p unsafe.Pointer) float64 { return float64(*(*float32)(p)) },
+			func(p unsafe.Pointer, f float64) { *(*float32)(p) = float32(f) }
+	case reflect.Float64:
+		return func(p unsafe.Pointer) float64 { return *(*float64)(p) },
+			func(p unsafe.Pointer, f float64) { *(*float64)(p) = f }
+	}
+	return nil, nil
+}
+
+func intCodec(t reflect.Type, size int, put func([]byte, int64) []byte, get func([]byte) int64) (fieldCodec, error) {
+	load, store := intAccess(t)
+	if load == nil {
+		return fieldCodec{}, fmt.Errorf("%w: %s is not an integer", ErrUnsupportedField, t)
+	}
+	if t.Size() == uintptr(size) {
+		// the common case, e.g. int32 as int: copy the bits directly
+		return sameWidthCodec(size), nil
+	}
+	return fixedCodec(size,
+		func(dst []byte, p unsafe.Pointer) []byte { return put(dst, load(p)) },
+		func(b []byte, p unsafe.Pointer) { store(p, get(b)) }), nil
+}
+
+// sameWidthCodec encodes an integer field as a big-endian integer of the same
+// size, which needs no sign extension.
+func sameWidthCodec(size int) fieldCodec {
+	switch size {
+	case 1:
+		return fixedCodec(1,
+			func(dst []byte, p unsafe.Pointer) []byte { return append(dst, *(*uint8)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*uint8)(p) = b[0] })
+	case 2:
+		return fixedCodec(2,
+			func(dst []byte, p unsafe.Pointer) []byte { return binary.BigEndian.AppendUint16(dst, *(*uint16)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*uint16)(p) = binary.BigEndian.Uint16(b) })
+	case 4:
+		return fixedCodec(4,
+			func(dst []byte, p unsafe.Pointer) []byte { return binary.BigEndian.AppendUint32(dst, *(*uint32)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*uint32)(p) = binary.BigEndian.Uint32(b) })
+	}
+	return fixedCodec(8,
+		func(dst []byte, p unsafe.Pointer) []byte { return binary.BigEndian.AppendUint64(dst, *(*uint64)(p)) },
+		func(b []byte, p unsafe.Pointer) { *(*uint64)(p) = binary.BigEndian.Uint64(b) })
+}
+
+// varCodec is intCodec for the variable length VarInt and VarLong.
+func varCodec(t reflect.Type, put func([]byte, int64) []byte, read func(io.Reader) (int64, error)) (fieldCodec, error) {
+	load, store := intAccess(t)
+	if load == nil {
+		return fieldCodec{}, fmt.Errorf("%w: %s is not an integer", ErrUnsupportedField, t)
+	}
+	return fieldCodec{
+		append: func(dst []byte, p unsafe.Pointer) ([]byte, error) { return put(dst, load(p)), nil },
+		decode: func(r io.Reader, p unsafe.Pointer) error {
+			n, err := read(r)
+			store(p, n)
+			return err
+		},
+	}, nil
+}
+
+func pointerCodec(t reflect.Type, elem fieldCodec) fieldCodec {
+	return fieldCodec{
+		append: func(dst []byte, p unsafe.Pointer) ([]byte, error) {
+			target := *(*unsafe.Pointer)(p)
+			if target == nil {
+				return dst, fmt.Errorf("%w: nil %s", ErrUnsupportedField, t)
+			}
+			return elem.append(dst, target)
+		},
+		decode: func(r io.Reader, p unsafe.Pointer) error {
+			v := reflect.NewAt(t, p).Elem()
+			if v.IsNil() {
+				v.Set(reflect.New(t.Elem()))
+			}
+			return elem.decode(r, v.UnsafePointer())
+		},
+	}
+}
+
+func arrayCodec(t reflect.Type, elem fieldCodec) fieldCodec {
+	size := t.Elem().Size()
+	return fieldCodec{
+		append: func(dst []byte, p unsafe.Pointer) ([]byte, error) {
+			v := reflect.NewAt(t, p).Elem()
+			dst = AppendVarInt(dst, VarInt(v.Len()))
+			base := v.UnsafePointer()
+			for i := 0; i < v.Len(); i++ {
+				var err error
+				if dst, err = elem.append(dst, unsafe.Add(base, uintptr(i)*size)); err != nil {
+					return dst, err
+				}
+			}
+			return dst, nil
+		},
+		decode: func(r io.Reader, p unsafe.Pointer) error {
+			n, err := ReadVarInt(r)
+			if err != nil {
+				return err
+			}
+			if n > MaxArrayLength {
+				return fmt.Errorf("%w: %d elements exceed %d", ErrInvalidLength, n, MaxArrayLength)
+			}
+			if err := checkLength(r, n); err != nil {
+				return err
+			}
+			s := reflect.MakeSlice(t, int(n), int(n))
+			base := s.UnsafePointer()
+			for i := 0; i < int(n); i++ {
+				if err := elem.decode(r, unsafe.Add(base, uintptr(i)*size)); err != nil {
+					return err
+				}
+			}
+			reflect.NewAt(t, p).Elem().Set(s)
+			return nil
+		},
+	}
+}
+
+func valueCodec(wire string) fieldCodec {
+	switch wire {
+	case "position":
+		return fixedCodec(8,
+			func(dst []byte, p unsafe.Pointer) []byte { return AppendPosition(dst, *(*Position)(p)) },
+			func(b []byte, p unsafe.Pointer) { *(*Position)(p) = unpackPosition(binary.BigEndian.Uint64(b)) })
+	case "uuid":
+		return fixedCodec(16,
+			func(dst []byte, p unsafe.Pointer) []byte { return AppendUUID(dst, *(*uuid.UUID)(p)) },
+			func(b []byte, p unsafe.Pointer) { copy((*uuid.UUID)(p)[:], b) })
+	case "slot":
+		return fieldCodec{
+			append: func(dst []byte, p unsafe.Pointer) ([]byte, error) {
+				slot := (*ItemSlot)(p)
+				return appendWith(dst, func(w io.Writer) error { return WriteSlot(w, *slot) })
+			},
+			decode: func(r io.Reader, p unsafe.Pointer) error {
+				s, err := ReadSlot(r)
+				*(*ItemSlot)(p) = s
+				return err
+			},
+		}
+	case "metadata":
+		return fieldCodec{
+			append: func(dst []byte, p unsafe.Pointer) ([]byte, error) {
+				m := *(*[]EntityMetadata)(p)
+				return appendWith(dst, func(w io.Writer) error { return WriteMetadata(w, m) })
+			},
+			decode: func(r io.Reader, p unsafe.Pointer) error {
+				m, err := ReadMetadata(r)
+				*(*[]EntityMetadata)(p) = m
+				return err
+			},
+		}
+	}
+	return fieldCodec{
+		append: func(dst []byte, p unsafe.Pointer) ([]byte, error) {
+			c := *(*nbt.Compound)(p)
+			return appendWith(dst, func(w io.Writer) error { return WriteNBT(w, c) })
+		},
+		decode: func(r io.Reader, p unsafe.Pointer) error {
+			c, err := ReadNBT(r)
+			*(*nbt.Compound)(p) = c
+			return err
+		},
+	}
+}
+
+// appendWriter collects the output of functions that only write to an
+// io.Writer, e.g. WriteSlot.
+type appendWriter struct {
+	b []byte
+}
+
+func (w *appendWriter) Write(p []byte) (int, error) {
+	w.b = append(w.b, p...)
+	return len(p), nil
+}
+
+func (w *appendWriter) WriteByte(c byte) error {
+	w.b = append(w.b, c)
+	return nil
+}
+
+func appendWith(dst []byte, write func(io.Writer) error) ([]byte, error) {
+	w := &appendWriter{b: dst}
+	err := write(w)
+	return w.b, err
+}
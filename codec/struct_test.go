@@ -0,0 +1,268 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type testHandshake struct {
+	ProtocolVersion VarInt
+	ServerAddress   string
+	ServerPort      uint16
+	NextState       VarInt
+}
+
+type testSpawn struct {
+	EntityID   int32 `mc:"varint"`
+	PlayerUUID uuid.UUID
+	Yaw        uint8 `mc:"angle"`
+	HasName    bool
+	Name       *string `mc:"optional=HasName"`
+	Records    []int32 `mc:"array,elem=varint"`
+	Location   Position
+	Cache      int    `mc:"-"`
+	Data       []byte `mc:"rest"`
+}
+
+type testNode struct {
+	Name     string
+	Children []testNode
+}
+
+func TestStructVectors(t *testing.T) {
+	name := "Notch"
+	tests := []struct {
+		name string
+		v    any
+		wire []byte
+	}{
+		{
+			// the Handshake payload a 1.8 client sends to localhost:25565
+			"handshake",
+			&testHandshake{47, "localhost", 25565, 2},
+			append(append([]byte{0x2f, 0x09}, "localhost"...), 0x63, 0xdd, 0x02),
+		},
+		{
+			"spawn",
+			&testSpawn{
+				EntityID:   300,
+				PlayerUUID: uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5"),
+				Yaw:        64,
+				HasName:    true,
+				Name:       &name,
+				Records:    []int32{1, -1},
+				Location:   Position{18357644, 831, -20882616},
+				Data:       []byte{0xca, 0xfe},
+			},
+			bytes.Join([][]byte{
+				{0xac, 0x02},
+				{0x06, 0x9a, 0x79, 0xf4, 0x44, 0xe9, 0x47, 0x26, 0xa5, 0xbe, 0xfc, 0xa9, 0x0e, 0x38, 0xaa, 0xf5},
+				{0x40},
+				{0x01, 0x05, 'N', 'o', 't', 'c', 'h'},
+				{0x02, 0x01, 0xff, 0xff, 0xff, 0xff, 0x0f},
+				{0x46, 0x07, 0x63, 0x0c, 0xfe, 0xc1, 0x5b, 0x48},
+				{0xca, 0xfe},
+			}, nil),
+		},
+		{
+			"self-referential",
+			&testNode{Name: "a", Children: []testNode{{Name: "b", Children: []testNode{}}}},
+			[]byte{0x01, 'a', 0x01, 0x01, 'b', 0x00},
+		},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		if err := EncodeStruct(buf, tt.v); err != nil {
+			t.Errorf("%s: EncodeStruct: %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), tt.wire) {
+			t.Errorf("%s: EncodeStruct = % x, want % x", tt.name, buf.Bytes(), tt.wire)
+		}
+
+		got := reflect.New(reflect.TypeOf(tt.v).Elem())
+		if err := DecodeStruct(plainReader{bytes.NewReader(tt.wire)}, got.Interface()); err != nil {
+			t.Errorf("%s: DecodeStruct: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Interface(), tt.v) {
+			t.Errorf("%s: DecodeStruct = %+v, want %+v", tt.name, got.Interface(), tt.v)
+		}
+	}
+}
+
+func TestStructOptionalAbsent(t *testing.T) {
+	v := &testSpawn{Records: []int32{}, Data: []byte{}}
+	buf := &bytes.Buffer{}
+	if err := EncodeStruct(buf, v); err != nil {
+		t.Fatal(err)
+	}
+	got := &testSpawn{}
+	if err := DecodeStruct(bytes.NewReader(buf.Bytes()), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.HasName || got.Name != nil {
+		t.Fatalf("absent optional decoded as %v", got.Name)
+	}
+}
+
+func TestStructArrayLimit(t *testing.T) {
+	type empty struct{}
+	type list struct {
+		Elems []empty
+	}
+	// 65537 zero-size elements need no bytes, so only the cap stops them
+	wire := []byte{0x81, 0x80, 0x04}
+	for _, r := range []io.Reader{bytes.NewReader(wire), plainReader{bytes.NewReader(wire)}} {
+		if err := DecodeStruct(r, &list{}); !errors.Is(err, ErrInvalidLength) {
+			t.Errorf("DecodeStruct error = %v, want ErrInvalidLength", err)
+		}
+	}
+}
+
+func TestStructUnsupported(t *testing.T) {
+	type bad struct {
+		C chan int
+	}
+	if err := EncodeStruct(&bytes.Buffer{}, &bad{}); !errors.Is(err, ErrUnsupportedField) {
+		t.Fatalf("EncodeStruct error = %v, want ErrUnsupportedField", err)
+	}
+	type badOptional struct {
+		Name *string `mc:"optional=Missing"`
+	}
+	if err := EncodeStruct(&bytes.Buffer{}, &badOptional{}); !errors.Is(err, ErrUnsupportedField) {
+		t.Fatalf("EncodeStruct error = %v, want ErrUnsupportedField", err)
+	}
+}
+
+// testJoinGame has the shape of packet.ClientJoinGame. The handwritten
+// functions below are what the packet used before struct tags.
+type testJoinGame struct {
+	EntityID         int32
+	Gamemode         uint8
+	Dimension        int8
+	Difficulty       uint8
+	MaxPlayers       uint8
+	LevelType        string
+	ReducedDebugInfo bool
+}
+
+func (j *testJoinGame) encode(w io.Writer) error {
+	if err := WriteInt(w, j.EntityID); err != nil {
+		return err
+	}
+	if err := WriteUByte(w, j.Gamemode); err != nil {
+		return err
+	}
+	if err := WriteByte(w, j.Dimension); err != nil {
+		return err
+	}
+	if err := WriteUByte(w, j.Difficulty); err != nil {
+		return err
+	}
+	if err := WriteUByte(w, j.MaxPlayers); err != nil {
+		return err
+	}
+	if err := WriteString(w, j.LevelType); err != nil {
+		return err
+	}
+	return WriteBool(w, j.ReducedDebugInfo)
+}
+
+func (j *testJoinGame) decode(r io.Reader) error {
+	var err error
+	if j.EntityID, err = ReadInt(r); err != nil {
+		return err
+	}
+	if j.Gamemode, err = ReadUByte(r); err != nil {
+		return err
+	}
+	if j.Dimension, err = ReadByte(r); err != nil {
+		return err
+	}
+	if j.Difficulty, err = ReadUByte(r); err != nil {
+		return err
+	}
+	if j.MaxPlayers, err = ReadUByte(r); err != nil {
+		return err
+	}
+	if j.LevelType, err = ReadString(r); err != nil {
+		return err
+	}
+	j.ReducedDebugInfo, err = ReadBool(r)
+	return err
+}
+
+var joinGame = testJoinGame{EntityID: 1234, Gamemode: 1, Difficulty: 2, MaxPlayers: 20, LevelType: "default"}
+
+func TestStructMatchesHandwritten(t *testing.T) {
+	want := &bytes.Buffer{}
+	if err := joinGame.encode(want); err != nil {
+		t.Fatal(err)
+	}
+	got := &bytes.Buffer{}
+	if err := EncodeStruct(got, &joinGame); err != nil || !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Fatalf("EncodeStruct = % x, %v, want % x", got.Bytes(), err, want.Bytes())
+	}
+	var decoded testJoinGame
+	if err := DecodeStruct(bytes.NewReader(want.Bytes()), &decoded); err != nil || decoded != joinGame {
+		t.Errorf("DecodeStruct = %+v, %v", decoded, err)
+	}
+}
+
+func BenchmarkEncodeStruct(b *testing.B) {
+	buf := &bytes.Buffer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := EncodeStruct(buf, &joinGame); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeHandwritten(b *testing.B) {
+	buf := &bytes.Buffer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := joinGame.encode(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeStruct(b *testing.B) {
+	buf := &bytes.Buffer{}
+	joinGame.encode(buf)
+	data := buf.Bytes()
+	r := bytes.NewReader(data)
+	var j testJoinGame
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		if err := DecodeStruct(r, &j); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeHandwritten(b *testing.B) {
+	buf := &bytes.Buffer{}
+	joinGame.encode(buf)
+	data := buf.Bytes()
+	r := bytes.NewReader(data)
+	var j testJoinGame
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		if err := j.decode(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
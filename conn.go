@@ -1,73 +1,294 @@
 package mcgotocol
 
 import (
-	s "bufio"
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rsa"
+	"errors"
+	"github.com/NaymDev/mcgotocol/bungeecord"
 	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/encryption"
 	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/proxyproto"
 	"github.com/NaymDev/mcgotocol/state"
 	"io"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// DefaultPort is used by Dial when the address does not contain a port.
+const DefaultPort = "25565"
+
 type Connection struct {
-	conn                      io.ReadWriter
-	reader                    *bufio.Reader
-	serverBoundPacketRegistry *state.PacketRegistry
+	conn                 io.ReadWriter
+	reader               *bufio.Reader
+	writer               io.Writer
+	registry             atomic.Pointer[state.Registry]
+	inbound              proto.Direction
+	compressionThreshold atomic.Int32
+	readTimeout          atomic.Int64
+	writeTimeout         atomic.Int64
+	maxFrameSize         int
+	strict               bool
+	rawPassthrough       bool
+	framesRead           bool
+	// readDeadline is the deadline readWire set for the current read.
+	readDeadline         time.Time
+	proxyHeader          *proxyproto.Header
+	bungeeCordForwarding bool
+	forwarded            *bungeecord.ForwardedData
+
+	autoState     bool
+	encryptionKey *rsa.PrivateKey
+	verifyToken   []byte
+	sharedSecret  []byte
+
+	interceptorMu        sync.Mutex
+	inboundInterceptors  []Interceptor
+	outboundInterceptors []Interceptor
+	frameHooks           []FrameHook
+	// pending holds packets injected by inbound interceptors.
+	pending []proto.Packet
+
+	// writeMu serializes encoding and writing of packets.
+	writeMu sync.Mutex
+	queue   *writeQueue
 }
 
+// NewConnection creates the server side of a connection: it decodes
+// server-bound packets.
 func NewConnection(conn io.ReadWriter, registry *state.Registry) *Connection {
-	return &Connection{
-		conn:                      conn,
-		reader:                    bufio.NewReader(conn),
-		serverBoundPacketRegistry: registry.ServerBound,
+	return newConnection(conn, registry, proto.ServerBound)
+}
+
+// NewClientConnection creates the client side of a connection: it decodes
+// client-bound packets, e.g. for bots, pingers or the upstream half of a
+// proxy.
+func NewClientConnection(conn io.ReadWriter, registry *state.Registry) *Connection {
+	return newConnection(conn, registry, proto.ClientBound)
+}
+
+func newConnection(conn io.ReadWriter, registry *state.Registry, inbound proto.Direction) *Connection {
+	c := &Connection{
+		conn:         conn,
+		reader:       bufio.NewReader(conn),
+		writer:       conn,
+		inbound:      inbound,
+		maxFrameSize: codec.DefaultMaxFrameSize,
 	}
+	c.registry.Store(registry)
+	c.compressionThreshold.Store(codec.CompressionDisabled)
+	return c
+}
+
+// Dial connects to a server and returns a client-side connection in the
+// given state (usually state.Handshake). DefaultPort is used if address has
+// no port.
+func Dial(address string, registry *state.Registry) (*Connection, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, DefaultPort)
+	}
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientConnection(conn, registry), nil
 }
 
 func (c *Connection) SetState(registry *state.Registry) {
-	c.serverBoundPacketRegistry = registry.ServerBound
+	c.registry.Store(registry)
 }
 
-func (c *Connection) ReadPacket() (proto.Packet, error) {
-	length, err := codec.ReadVarInt(c.reader)
+func (c *Connection) Registry() *state.Registry {
+	return c.registry.Load()
+}
+
+// IsClient reports whether this is the client side of the connection.
+func (c *Connection) IsClient() bool {
+	return c.inbound == proto.ClientBound
+}
+
+func (c *Connection) inboundRegistry() *state.PacketRegistry {
+	if c.inbound == proto.ClientBound {
+		return c.Registry().ClientBound
+	}
+	return c.Registry().ServerBound
+}
+
+// SetCompressionThreshold switches the framing of all following packets to
+// the compressed format. It must be called right after the Set Compression
+// packet has been written (server) or read (client). A negative threshold
+// disables compression again.
+func (c *Connection) SetCompressionThreshold(threshold int) {
+	if threshold < 0 {
+		threshold = codec.CompressionDisabled
+	}
+	c.compressionThreshold.Store(int32(threshold))
+}
+
+func (c *Connection) CompressionThreshold() int {
+	return int(c.compressionThreshold.Load())
+}
+
+// EnableEncryption wraps the connection in AES/CFB8 using sharedSecret as key
+// and IV. Every byte read or written afterwards is transparently
+// (de)crypted. Bytes that were already buffered by the reader are decrypted
+// as well, so it is safe to call this right after reading the Encryption
+// Response. Queued packets are flushed unencrypted first. sharedSecret must
+// be 16 bytes long, otherwise encryption.ErrInvalidSecretLength is returned.
+func (c *Connection) EnableEncryption(sharedSecret []byte) error {
+	if len(sharedSecret) != encryption.SharedSecretSize {
+		return encryption.ErrInvalidSecretLength
+	}
+	encrypt, decrypt, err := encryption.NewStreams(sharedSecret)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.flushLocked(); err != nil {
+		return err
+	}
+	c.reader = bufio.NewReader(cipher.StreamReader{S: decrypt, R: c.reader})
+	c.writer = cipher.StreamWriter{S: encrypt, W: c.writer}
+	return nil
+}
+
+// SetMaxFrameSize limits the length of incoming frames. Longer frames are
+// rejected with a *codec.FrameSizeError before any memory is allocated.
+func (c *Connection) SetMaxFrameSize(n int) {
+	c.maxFrameSize = n
+}
+
+// SetStrict makes ReadPacket fail with *state.TrailingBytes if a packet's
+// Decode leaves bytes of the frame unread.
+func (c *Connection) SetStrict(strict bool) {
+	c.strict = strict
+}
+
+// SetRawPassthrough makes ReadPacket return a *proto.RawPacket for packet IDs
+// that are not registered in the current state instead of failing with
+// *state.UnknownPacketID. Proxies can use their own state.Registry that only
+// contains the packets they care about and forward everything else.
+func (c *Connection) SetRawPassthrough(enabled bool) {
+	c.rawPassthrough = enabled
+}
+
+// ReadPacket reads and decodes the next packet. On the server side the first
+// read in Handshake state returns a *packet.LegacyServerListPing if a
+// pre-Netty client pinged, see WriteLegacyPong.
+func (c *Connection) ReadPacket() (proto.Packet, error) {
+	return c.ReadPacketContext(context.Background())
+}
+
+// readPacket reads and decodes the next packet. It also returns the state the
+// packet was decoded in; auto state has not acted on the packet yet.
+func (c *Connection) readPacket(ctx context.Context) (proto.Packet, *state.Registry, error) {
+	current := c.Registry()
+	if c.expectsLegacyPing() {
+		if ping, ok, err := c.readLegacyPing(ctx); ok || err != nil {
+			return ping, current, err
+		}
 	}
+	c.framesRead = true
 
-	buf := make([]byte, length)
-	_, err = io.ReadFull(c.reader, buf)
+	buf, err := codec.ReadLimitedFrame(c.reader, c.CompressionThreshold(), c.maxFrameSize)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	c.runFrameHooks(c.inbound, buf)
 
 	br := bytes.NewReader(buf)
 
 	packetID, err := codec.ReadVarInt(br)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return c.serverBoundPacketRegistry.Decode(int32(packetID), br)
+	registry := current.ServerBound
+	if c.inbound == proto.ClientBound {
+		registry = current.ClientBound
+	}
+	pkt, err := registry.Decode(int32(packetID), br)
+	var unknown *state.UnknownPacketID
+	if c.rawPassthrough && errors.As(err, &unknown) {
+		return &proto.RawPacket{
+			PacketID: int32(packetID),
+			Data:     buf[len(buf)-br.Len():],
+		}, current, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.strict && br.Len() > 0 {
+		return nil, nil, &state.TrailingBytes{
+			PacketID:  int32(packetID),
+			State:     registry.State,
+			Remaining: br.Len(),
+		}
+	}
+	if c.IsClient() {
+		if err := disconnectError(pkt); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := c.handleForwarding(pkt); err != nil {
+		return nil, nil, err
+	}
+	return pkt, current, nil
 }
 
+// WritePacket is safe for concurrent use. If a write queue was started the
+// packet is only queued, see StartWriteQueue.
 func (c *Connection) WritePacket(p proto.Packet) error {
-	buf := &bytes.Buffer{}
+	return c.writePacket(context.Background(), p)
+}
 
-	if err := codec.WriteVarInt(buf, codec.VarInt(p.ID())); err != nil {
+func (c *Connection) writePacket(ctx context.Context, p proto.Packet) error {
+	packets, err := c.intercept(true, c.Registry(), p)
+	if err != nil {
 		return err
 	}
+	for _, pkt := range packets {
+		if err := c.writeWire(ctx, pkt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if err := p.Encode(buf); err != nil {
+func (c *Connection) writeWire(ctx context.Context, p proto.Packet) error {
+	packetData, err := codec.EncodePacket(p)
+	if err != nil {
 		return err
 	}
 
-	packetData := buf.Bytes()
-	if err := codec.WriteVarInt(c.conn, codec.VarInt(len(packetData))); err != nil {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.checkOutbound(p); err != nil {
 		return err
 	}
-
-	_, err := c.conn.Write(packetData)
-	return err
+	frame, err := codec.AppendFrame(nil, packetData, c.CompressionThreshold())
+	if err != nil {
+		return err
+	}
+	if c.queue != nil {
+		err = c.push(ctx, c.queue, frame)
+	} else {
+		err = c.writeFrame(ctx, frame)
+	}
+	if err != nil {
+		return err
+	}
+	c.runFrameHooks(c.outbound(), packetData)
+	c.trackOutbound(p)
+	return nil
 }
 
 // RawConn Is not recommended for reading because it's not buffered.
@@ -75,20 +296,35 @@ func (c *Connection) RawConn() io.ReadWriter {
 	return c.conn
 }
 
+// Close stops the write queue without flushing it and closes the underlying
+// connection.
 func (c *Connection) Close() error {
+	if c.queue != nil {
+		c.queue.close()
+	}
 	if closer, ok := c.conn.(io.Closer); ok {
 		return closer.Close()
 	}
 	return nil
 }
 
+// RemoteAddr returns the address of the peer, or of the original client if
+// BungeeCord forwarding data or a PROXY protocol header was read.
 func (c *Connection) RemoteAddr() string {
+	if c.forwarded != nil {
+		return c.forwardedAddr()
+	}
+	if c.proxyHeader != nil && c.proxyHeader.Source != nil {
+		return c.proxyHeader.Source.String()
+	}
 	if addrProvider, ok := c.conn.(net.Conn); ok {
 		return addrProvider.RemoteAddr().String()
 	}
 	return "unknown"
 }
 
+// State describes the current state and the direction of packets that are
+// decoded, e.g. "Play ServerBound".
 func (c *Connection) State() string {
-	return c.serverBoundPacketRegistry.State
+	return c.inboundRegistry().State
 }
@@ -0,0 +1,59 @@
+package mcgotocol
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/state"
+)
+
+func TestCompressionThreshold(t *testing.T) {
+	state.InitRegistries()
+	const threshold = 64
+	// Plugin Message: id, VarInt length of "MC|Brand", the channel, then data
+	header := 1 + 1 + len("MC|Brand")
+	for _, size := range []int{threshold - 1, threshold, threshold + 1, 1024} {
+		wire := &bytes.Buffer{}
+		server := NewConnection(readWriter{&bytes.Buffer{}, wire}, state.Play)
+		server.SetCompressionThreshold(threshold)
+		sent := &packet.ClientPluginMessage{Channel: "MC|Brand", Data: bytes.Repeat([]byte{'x'}, size-header)}
+		if err := server.WritePacket(sent); err != nil {
+			t.Fatal(err)
+		}
+
+		r := bytes.NewReader(wire.Bytes())
+		codec.ReadVarInt(r)
+		dataLength, _ := codec.ReadVarInt(r)
+		if size < threshold && dataLength != 0 || size >= threshold && int(dataLength) != size {
+			t.Errorf("%d byte packet: data length = %d", size, dataLength)
+		}
+
+		client := NewClientConnection(readWriter{wire, &bytes.Buffer{}}, state.Play)
+		client.SetCompressionThreshold(threshold)
+		got, err := client.ReadPacket()
+		if err != nil || !reflect.DeepEqual(got, sent) {
+			t.Errorf("%d byte packet: ReadPacket = %+v, %v", size, got, err)
+		}
+	}
+}
+
+func TestCompressionDisabled(t *testing.T) {
+	state.InitRegistries()
+	wire := &bytes.Buffer{}
+	server := NewConnection(readWriter{&bytes.Buffer{}, wire}, state.Play)
+	server.SetCompressionThreshold(64)
+	server.SetCompressionThreshold(-5)
+	if server.CompressionThreshold() != codec.CompressionDisabled {
+		t.Fatalf("CompressionThreshold = %d", server.CompressionThreshold())
+	}
+	if err := server.WritePacket(&packet.ClientKeepAlive{KeepAliveID: 7}); err != nil {
+		t.Fatal(err)
+	}
+	// length, packet id 0x00, VarInt id; no data length field
+	if want := []byte{0x02, 0x00, 0x07}; !bytes.Equal(wire.Bytes(), want) {
+		t.Fatalf("frame = % x, want % x", wire.Bytes(), want)
+	}
+}
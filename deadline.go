@@ -0,0 +1,184 @@
+package mcgotocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state"
+	"net"
+	"os"
+	"time"
+)
+
+// TimeoutError is returned when a read or write did not finish before the
+// idle timeout of the connection or the deadline of its context expired. It
+// is never returned for malformed packets.
+type TimeoutError struct {
+	Op  string
+	Err error
+}
+
+var _ net.Error = (*TimeoutError)(nil)
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out: %v", e.Op, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+func (e *TimeoutError) Temporary() bool {
+	return false
+}
+
+// deadliner is implemented by net.Conn. Contexts and timeouts only take
+// effect on connections that support deadlines.
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+// aLongTimeAgo is used to interrupt a blocked read or write immediately.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// SetReadTimeout bounds how long a single ReadPacket may wait for data.
+// Zero disables the timeout.
+func (c *Connection) SetReadTimeout(d time.Duration) {
+	c.readTimeout.Store(int64(d))
+}
+
+// SetWriteTimeout bounds how long writing a single packet to the socket may
+// take. Zero disables the timeout.
+func (c *Connection) SetWriteTimeout(d time.Duration) {
+	c.writeTimeout.Store(int64(d))
+}
+
+// ReadPacketContext is like ReadPacket but gives up when ctx is done. A
+// cancelled context returns ctx.Err(), an expired deadline or read timeout a
+// *TimeoutError. The connection should be closed after either, since a frame
+// may have been read partially.
+func (c *Connection) ReadPacketContext(ctx context.Context) (proto.Packet, error) {
+	for {
+		if len(c.pending) > 0 {
+			p := c.pending[0]
+			c.pending = c.pending[1:]
+			return p, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		p, registry, err := c.readWire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		packets, err := c.intercept(false, registry, p)
+		if err != nil {
+			return nil, err
+		}
+		// auto state follows the wire, whatever the interceptors made of p
+		if err := c.trackInbound(p); err != nil {
+			return nil, err
+		}
+		if len(packets) > 0 {
+			c.pending = packets[1:]
+			return packets[0], nil
+		}
+	}
+}
+
+func (c *Connection) readWire(ctx context.Context) (proto.Packet, *state.Registry, error) {
+	timeout := time.Duration(c.readTimeout.Load())
+	c.readDeadline = time.Time{}
+	if d, ok := c.conn.(deadliner); ok && (ctx.Done() != nil || timeout > 0) {
+		c.readDeadline = deadlineFor(ctx, timeout)
+		d.SetReadDeadline(c.readDeadline)
+		stop := watchContext(ctx, func() {
+			d.SetReadDeadline(aLongTimeAgo)
+		})
+		defer d.SetReadDeadline(time.Time{})
+		defer stop()
+	}
+
+	p, registry, err := c.readPacket(ctx)
+	return p, registry, timeoutError("read", ctx, err)
+}
+
+// WritePacketContext is like WritePacket but gives up when ctx is done while
+// waiting for room in the write queue or for the socket.
+func (c *Connection) WritePacketContext(ctx context.Context, p proto.Packet) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.writePacket(ctx, p)
+}
+
+// writeFrame writes a complete frame to the (possibly encrypted) writer. It
+// must only be called by the holder of writeMu or by the queue goroutine.
+func (c *Connection) writeFrame(ctx context.Context, frame []byte) error {
+	timeout := time.Duration(c.writeTimeout.Load())
+	if d, ok := c.conn.(deadliner); ok && (ctx.Done() != nil || timeout > 0) {
+		d.SetWriteDeadline(deadlineFor(ctx, timeout))
+		stop := watchContext(ctx, func() {
+			d.SetWriteDeadline(aLongTimeAgo)
+		})
+		defer d.SetWriteDeadline(time.Time{})
+		defer stop()
+	}
+
+	_, err := c.writer.Write(frame)
+	return timeoutError("write", ctx, err)
+}
+
+func deadlineFor(ctx context.Context, timeout time.Duration) time.Time {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	return deadline
+}
+
+// watchContext calls f once ctx is done. The returned stop function must be
+// called when the operation finished; it waits for a running f so that f
+// cannot affect a later operation.
+func watchContext(ctx context.Context, f func()) (stop func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopFunc := context.AfterFunc(ctx, func() {
+		f()
+		close(done)
+	})
+	return func() {
+		if !stopFunc() {
+			<-done
+		}
+	}
+}
+
+func timeoutError(op string, ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return &TimeoutError{Op: op, Err: ctxErr}
+		}
+		return ctxErr
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &TimeoutError{Op: op, Err: err}
+	}
+	return err
+}
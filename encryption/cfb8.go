@@ -0,0 +1,86 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+)
+
+// SharedSecretSize is the length of the shared secret Minecraft uses as
+// AES-128 key and IV.
+const SharedSecretSize = 16
+
+var ErrInvalidSecretLength = errors.New("encryption: shared secret must be 16 bytes")
+
+// cfb8 implements the 8-bit cipher feedback mode Minecraft uses for its
+// stream encryption. The standard library only ships full-block CFB.
+type cfb8 struct {
+	block   cipher.Block
+	iv      []byte
+	tmp     []byte
+	decrypt bool
+}
+
+var _ cipher.Stream = (*cfb8)(nil)
+
+func newCFB8(block cipher.Block, iv []byte, decrypt bool) (*cfb8, error) {
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("encryption: IV length %d must equal block size %d", len(iv), block.BlockSize())
+	}
+	c := &cfb8{
+		block:   block,
+		iv:      make([]byte, len(iv)),
+		tmp:     make([]byte, block.BlockSize()),
+		decrypt: decrypt,
+	}
+	copy(c.iv, iv)
+	return c, nil
+}
+
+func NewCFB8Encrypter(block cipher.Block, iv []byte) (cipher.Stream, error) {
+	return newCFB8(block, iv, false)
+}
+
+func NewCFB8Decrypter(block cipher.Block, iv []byte) (cipher.Stream, error) {
+	return newCFB8(block, iv, true)
+}
+
+func (c *cfb8) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("encryption: output smaller than input")
+	}
+	for i := range src {
+		c.block.Encrypt(c.tmp, c.iv)
+		in := src[i]
+		out := in ^ c.tmp[0]
+		copy(c.iv, c.iv[1:])
+		if c.decrypt {
+			c.iv[len(c.iv)-1] = in
+		} else {
+			c.iv[len(c.iv)-1] = out
+		}
+		dst[i] = out
+	}
+}
+
+// NewStreams returns the encrypting and decrypting streams for a shared
+// secret. Minecraft uses the secret as both the AES key and the IV.
+// Secrets that are not SharedSecretSize bytes long are rejected with
+// ErrInvalidSecretLength.
+func NewStreams(sharedSecret []byte) (encrypt, decrypt cipher.Stream, err error) {
+	if len(sharedSecret) != SharedSecretSize {
+		return nil, nil, ErrInvalidSecretLength
+	}
+	block, err := aes.NewCipher(sharedSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	if encrypt, err = NewCFB8Encrypter(block, sharedSecret); err != nil {
+		return nil, nil, err
+	}
+	if decrypt, err = NewCFB8Decrypter(block, sharedSecret); err != nil {
+		return nil, nil, err
+	}
+	return encrypt, decrypt, nil
+}
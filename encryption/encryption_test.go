@@ -0,0 +1,152 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestServerHash(t *testing.T) {
+	// the examples of the protocol documentation: the digest of the name alone
+	for name, want := range map[string]string{
+		"Notch": "4ed1f46bbe04bc756bcb17c0c7ce3e4632f06a48",
+		"jeb_":  "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1",
+		"simon": "88e16a1019277b15d58faf0541e11910eb756f6",
+	} {
+		if got := ServerHash(name, nil, nil); got != want {
+			t.Errorf("ServerHash(%q) = %s, want %s", name, got, want)
+		}
+	}
+	// the parts are hashed in order without separators
+	if ServerHash("", []byte("Not"), []byte("ch")) != ServerHash("Notch", nil, nil) {
+		t.Error("ServerHash does not hash the concatenation of its arguments")
+	}
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestCFB8Vector(t *testing.T) {
+	// NIST SP 800-38A, F.3.7 CFB8-AES128.Encrypt
+	key := mustHex("2b7e151628aed2a6abf7158809cf4f3c")
+	iv := mustHex("000102030405060708090a0b0c0d0e0f")
+	plain := mustHex("6bc1bee22e409f96e93d7e117393172aae2d")
+	cipherText := mustHex("3b79424c9c0dd436bace9e0ed4586a4f32b9")
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := NewCFB8Encrypter(block, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(plain))
+	// a stream cipher must not depend on how the input is split
+	enc.XORKeyStream(got[:5], plain[:5])
+	enc.XORKeyStream(got[5:], plain[5:])
+	if !bytes.Equal(got, cipherText) {
+		t.Errorf("encrypt = %x, want %x", got, cipherText)
+	}
+
+	dec, err := NewCFB8Decrypter(block, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec.XORKeyStream(got, got)
+	if !bytes.Equal(got, plain) {
+		t.Errorf("decrypt = %x, want %x", got, plain)
+	}
+
+	if _, err := NewCFB8Encrypter(block, iv[:8]); err == nil {
+		t.Error("NewCFB8Encrypter accepted a short IV")
+	}
+}
+
+func TestStreamsRoundTrip(t *testing.T) {
+	secret, err := RandomBytes(SharedSecretSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypt, _, err := NewStreams(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, decrypt, err := NewStreams(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := []byte("\x0f\x00\x2f\x09localhost\x63\xdd\x02")
+	buf := make([]byte, len(plain))
+	encrypt.XORKeyStream(buf, plain)
+	if bytes.Equal(buf, plain) {
+		t.Fatal("XORKeyStream did not change the data")
+	}
+	for i := range buf {
+		decrypt.XORKeyStream(buf[i:i+1], buf[i:i+1])
+	}
+	if !bytes.Equal(buf, plain) {
+		t.Errorf("round trip = %x, want %x", buf, plain)
+	}
+
+	for _, n := range []int{0, 15, 17, 32} {
+		if _, _, err := NewStreams(make([]byte, n)); !errors.Is(err, ErrInvalidSecretLength) {
+			t.Errorf("NewStreams with %d bytes: %v", n, err)
+		}
+	}
+}
+
+func TestRSARoundTrip(t *testing.T) {
+	priv, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := MarshalPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePublicKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(&priv.PublicKey) {
+		t.Fatal("ParsePublicKey returned another key")
+	}
+
+	secret := []byte("0123456789abcdef")
+	encrypted, err := Encrypt(pub, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encrypted) != KeySize/8 {
+		t.Errorf("encrypted length = %d, want %d", len(encrypted), KeySize/8)
+	}
+	decrypted, err := Decrypt(priv, encrypted)
+	if err != nil || !bytes.Equal(decrypted, secret) {
+		t.Errorf("Decrypt = %x, %v", decrypted, err)
+	}
+
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err = x509.MarshalPKIXPublicKey(&ec.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParsePublicKey(der); !errors.Is(err, ErrNotRSAPublicKey) {
+		t.Errorf("ParsePublicKey of an EC key = %v", err)
+	}
+}
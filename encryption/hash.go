@@ -0,0 +1,36 @@
+package encryption
+
+import (
+	"crypto/sha1"
+	"math/big"
+)
+
+// ServerHash computes the hash sent to the session server. Minecraft
+// interprets the SHA-1 digest as a signed two's-complement number and prints
+// it in hexadecimal, so the result may start with a minus sign.
+func ServerHash(serverID string, sharedSecret, publicKey []byte) string {
+	h := sha1.New()
+	h.Write([]byte(serverID))
+	h.Write(sharedSecret)
+	h.Write(publicKey)
+	digest := h.Sum(nil)
+
+	negative := digest[0]&0x80 != 0
+	if negative {
+		// two's complement
+		carry := true
+		for i := len(digest) - 1; i >= 0; i-- {
+			digest[i] = ^digest[i]
+			if carry {
+				digest[i]++
+				carry = digest[i] == 0
+			}
+		}
+	}
+
+	hash := new(big.Int).SetBytes(digest).Text(16)
+	if negative {
+		return "-" + hash
+	}
+	return hash
+}
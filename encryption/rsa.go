@@ -0,0 +1,57 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+)
+
+// KeySize is the RSA modulus size used by the vanilla server.
+const KeySize = 1024
+
+var ErrNotRSAPublicKey = errors.New("public key is not an RSA key")
+
+// GenerateKey creates the server keypair that is sent (DER encoded) in the
+// Encryption Request.
+func GenerateKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, KeySize)
+}
+
+// MarshalPublicKey encodes pub as ASN.1 DER SubjectPublicKeyInfo, the format
+// expected in the Encryption Request.
+func MarshalPublicKey(pub *rsa.PublicKey) ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(pub)
+}
+
+func ParsePublicKey(der []byte) (*rsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrNotRSAPublicKey
+	}
+	return pub, nil
+}
+
+// Encrypt encrypts data with PKCS#1 v1.5 padding, as done by the client for
+// the shared secret and verify token.
+func Encrypt(pub *rsa.PublicKey, data []byte) ([]byte, error) {
+	return rsa.EncryptPKCS1v15(rand.Reader, pub, data)
+}
+
+func Decrypt(priv *rsa.PrivateKey, data []byte) ([]byte, error) {
+	return rsa.DecryptPKCS1v15(rand.Reader, priv, data)
+}
+
+// RandomBytes returns n cryptographically random bytes, e.g. for the verify
+// token (4 bytes) or the shared secret (16 bytes).
+func RandomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
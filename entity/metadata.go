@@ -0,0 +1,231 @@
+// Package entity provides typed access to 1.8 entity metadata so callers do
+// not have to index into []codec.EntityMetadata by hand.
+package entity
+
+import "github.com/NaymDev/mcgotocol/codec"
+
+// Metadata indices shared by all entities, living entities, players and
+// armor stands. Some indices mean different things for different entities,
+// e.g. IndexSkinParts and IndexArmorStandFlags.
+const (
+	IndexFlags             = 0  // byte, Flags
+	IndexAir               = 1  // short
+	IndexCustomName        = 2  // string
+	IndexCustomNameVisible = 3  // byte
+	IndexSilent            = 4  // byte
+	IndexHealth            = 6  // float, living entities
+	IndexPotionColor       = 7  // int, living entities
+	IndexPotionAmbient     = 8  // byte, living entities
+	IndexArrows            = 9  // byte, living entities
+	IndexNoAI              = 15 // byte, living entities
+	IndexSkinParts         = 10 // byte, players, SkinParts
+	IndexAbsorption        = 17 // float, players
+	IndexScore             = 18 // int, players
+	IndexArmorStandFlags   = 10 // byte, armor stands, ArmorStandFlags
+	IndexHeadRotation      = 11 // rotation, armor stands
+	IndexBodyRotation      = 12 // rotation, armor stands
+	IndexLeftArmRotation   = 13 // rotation, armor stands
+	IndexRightArmRotation  = 14 // rotation, armor stands
+	IndexLeftLegRotation   = 15 // rotation, armor stands
+	IndexRightLegRotation  = 16 // rotation, armor stands
+)
+
+// Flags is the bit field at IndexFlags.
+type Flags uint8
+
+const (
+	FlagOnFire    Flags = 0x01
+	FlagSneaking  Flags = 0x02
+	FlagSprinting Flags = 0x08
+	FlagUsingItem Flags = 0x10 // eating, drinking, blocking
+	FlagInvisible Flags = 0x20
+)
+
+func (f Flags) Has(flag Flags) bool {
+	return f&flag != 0
+}
+
+// SkinParts is the bit field of displayed skin layers at IndexSkinParts.
+type SkinParts uint8
+
+const (
+	SkinCape        SkinParts = 0x01
+	SkinJacket      SkinParts = 0x02
+	SkinLeftSleeve  SkinParts = 0x04
+	SkinRightSleeve SkinParts = 0x08
+	SkinLeftPants   SkinParts = 0x10
+	SkinRightPants  SkinParts = 0x20
+	SkinHat         SkinParts = 0x40
+
+	SkinAll SkinParts = 0x7F
+)
+
+func (s SkinParts) Has(part SkinParts) bool {
+	return s&part != 0
+}
+
+// ArmorStandFlags is the bit field at IndexArmorStandFlags.
+type ArmorStandFlags uint8
+
+const (
+	ArmorStandSmall       ArmorStandFlags = 0x01
+	ArmorStandHasGravity  ArmorStandFlags = 0x02
+	ArmorStandHasArms     ArmorStandFlags = 0x04
+	ArmorStandNoBasePlate ArmorStandFlags = 0x08
+	ArmorStandMarker      ArmorStandFlags = 0x10
+)
+
+func (a ArmorStandFlags) Has(flag ArmorStandFlags) bool {
+	return a&flag != 0
+}
+
+// Metadata wraps the entries of an entity's metadata, e.g.
+// entity.Metadata(spawn.Metadata).Flags().
+type Metadata []codec.EntityMetadata
+
+// Get returns the entry at index.
+func (m Metadata) Get(index byte) (codec.EntityMetadata, bool) {
+	for _, e := range m {
+		if e.Index == index {
+			return e, true
+		}
+	}
+	return codec.EntityMetadata{}, false
+}
+
+// Set replaces the entry at index or appends a new one.
+func (m *Metadata) Set(index byte, typ codec.MetadataType, value interface{}) {
+	entry := codec.EntityMetadata{Index: index, Type: typ, Value: value}
+	for i, e := range *m {
+		if e.Index == index {
+			(*m)[i] = entry
+			return
+		}
+	}
+	*m = append(*m, entry)
+}
+
+func (m Metadata) byteAt(index byte) (uint8, bool) {
+	e, ok := m.Get(index)
+	if !ok || e.Type != codec.MetaByte {
+		return 0, false
+	}
+	v, ok := e.Value.(int8)
+	return uint8(v), ok
+}
+
+func (m *Metadata) setByte(index byte, v uint8) {
+	m.Set(index, codec.MetaByte, int8(v))
+}
+
+func (m Metadata) Flags() Flags {
+	v, _ := m.byteAt(IndexFlags)
+	return Flags(v)
+}
+
+func (m *Metadata) SetFlags(f Flags) {
+	m.setByte(IndexFlags, uint8(f))
+}
+
+// SetFlag sets or clears a single flag, keeping the others.
+func (m *Metadata) SetFlag(flag Flags, on bool) {
+	f := m.Flags()
+	if on {
+		f |= flag
+	} else {
+		f &^= flag
+	}
+	m.SetFlags(f)
+}
+
+func (m Metadata) Sneaking() bool  { return m.Flags().Has(FlagSneaking) }
+func (m Metadata) Sprinting() bool { return m.Flags().Has(FlagSprinting) }
+func (m Metadata) Invisible() bool { return m.Flags().Has(FlagInvisible) }
+func (m Metadata) OnFire() bool    { return m.Flags().Has(FlagOnFire) }
+
+func (m Metadata) Air() (int16, bool) {
+	e, ok := m.Get(IndexAir)
+	if !ok || e.Type != codec.MetaShort {
+		return 0, false
+	}
+	v, ok := e.Value.(int16)
+	return v, ok
+}
+
+func (m *Metadata) SetAir(air int16) {
+	m.Set(IndexAir, codec.MetaShort, air)
+}
+
+func (m Metadata) CustomName() (string, bool) {
+	e, ok := m.Get(IndexCustomName)
+	if !ok || e.Type != codec.MetaString {
+		return "", false
+	}
+	v, ok := e.Value.(string)
+	return v, ok
+}
+
+func (m *Metadata) SetCustomName(name string) {
+	m.Set(IndexCustomName, codec.MetaString, name)
+}
+
+func (m Metadata) CustomNameVisible() bool {
+	v, _ := m.byteAt(IndexCustomNameVisible)
+	return v != 0
+}
+
+func (m *Metadata) SetCustomNameVisible(visible bool) {
+	var v uint8
+	if visible {
+		v = 1
+	}
+	m.setByte(IndexCustomNameVisible, v)
+}
+
+// Health is only present for living entities.
+func (m Metadata) Health() (float32, bool) {
+	e, ok := m.Get(IndexHealth)
+	if !ok || e.Type != codec.MetaFloat {
+		return 0, false
+	}
+	v, ok := e.Value.(float32)
+	return v, ok
+}
+
+func (m *Metadata) SetHealth(health float32) {
+	m.Set(IndexHealth, codec.MetaFloat, health)
+}
+
+// SkinParts is only meaningful for players.
+func (m Metadata) SkinParts() SkinParts {
+	v, _ := m.byteAt(IndexSkinParts)
+	return SkinParts(v)
+}
+
+func (m *Metadata) SetSkinParts(parts SkinParts) {
+	m.setByte(IndexSkinParts, uint8(parts))
+}
+
+// ArmorStandFlags is only meaningful for armor stands.
+func (m Metadata) ArmorStandFlags() ArmorStandFlags {
+	v, _ := m.byteAt(IndexArmorStandFlags)
+	return ArmorStandFlags(v)
+}
+
+func (m *Metadata) SetArmorStandFlags(flags ArmorStandFlags) {
+	m.setByte(IndexArmorStandFlags, uint8(flags))
+}
+
+// Rotation returns an armor stand pose entry such as IndexHeadRotation.
+func (m Metadata) Rotation(index byte) ([3]float32, bool) {
+	e, ok := m.Get(index)
+	if !ok || e.Type != codec.MetaRotation {
+		return [3]float32{}, false
+	}
+	v, ok := e.Value.([3]float32)
+	return v, ok
+}
+
+func (m *Metadata) SetRotation(index byte, rotation [3]float32) {
+	m.Set(index, codec.MetaRotation, rotation)
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/NaymDev/mcgotocol/codec"
+)
+
+func TestMetadataAccessors(t *testing.T) {
+	// a sneaking player on fire with all skin parts but the cape
+	wire := []byte{0x00, 0x03, 0x21, 0x01, 0x2c, 0x66, 0x41, 0x90, 0x00, 0x00, 0x0a, 0x7e, 0x7f}
+	entries, err := codec.ReadMetadata(bytes.NewReader(wire))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Metadata(entries)
+	if !m.Sneaking() || !m.OnFire() || m.Sprinting() || m.Invisible() {
+		t.Errorf("Flags = %#x", m.Flags())
+	}
+	if air, ok := m.Air(); !ok || air != 300 {
+		t.Errorf("Air = %d, %v", air, ok)
+	}
+	if health, ok := m.Health(); !ok || health != 18 {
+		t.Errorf("Health = %v, %v", health, ok)
+	}
+	if parts := m.SkinParts(); parts.Has(SkinCape) || !parts.Has(SkinHat) {
+		t.Errorf("SkinParts = %#x", parts)
+	}
+	if _, ok := m.CustomName(); ok {
+		t.Error("CustomName present")
+	}
+
+	var built Metadata
+	built.SetFlag(FlagSneaking, true)
+	built.SetFlag(FlagOnFire, true)
+	built.SetAir(300)
+	built.SetHealth(18)
+	built.SetSkinParts(SkinAll &^ SkinCape)
+	buf := &bytes.Buffer{}
+	if err := codec.WriteMetadata(buf, built); err != nil || !bytes.Equal(buf.Bytes(), wire) {
+		t.Errorf("WriteMetadata = % x, %v", buf.Bytes(), err)
+	}
+
+	built.SetFlag(FlagOnFire, false)
+	if !reflect.DeepEqual(built[0], codec.EntityMetadata{Index: IndexFlags, Type: codec.MetaByte, Value: int8(FlagSneaking)}) {
+		t.Errorf("SetFlag = %+v", built[0])
+	}
+}
@@ -0,0 +1,50 @@
+package mcgotocol
+
+import (
+	"github.com/NaymDev/mcgotocol/bungeecord"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/proto"
+	"net"
+)
+
+// SetBungeeCordForwarding makes the server side parse BungeeCord IP
+// forwarding data out of the ServerHandshake. The handshake returned by
+// ReadPacket then only carries the real host in ServerAddress, RemoteAddr
+// reports the forwarded client IP and Forwarded exposes UUID and properties
+// for the login. A handshake without forwarding data fails with
+// bungeecord.ErrNotForwarded.
+func (c *Connection) SetBungeeCordForwarding(enabled bool) {
+	c.bungeeCordForwarding = enabled
+}
+
+// Forwarded returns the BungeeCord forwarding data, or nil.
+func (c *Connection) Forwarded() *bungeecord.ForwardedData {
+	return c.forwarded
+}
+
+func (c *Connection) handleForwarding(p proto.Packet) error {
+	if !c.bungeeCordForwarding || c.IsClient() {
+		return nil
+	}
+	handshake, ok := p.(*packet.ServerHandshake)
+	if !ok || packet.HandshakeIntent(handshake.NextState) != packet.LoginHandshakeIntent {
+		return nil
+	}
+	data, err := bungeecord.Parse(handshake.ServerAddress)
+	if err != nil {
+		return err
+	}
+	handshake.ServerAddress = data.Host
+	c.forwarded = data
+	return nil
+}
+
+func (c *Connection) forwardedAddr() string {
+	port := "0"
+	if conn, ok := c.conn.(net.Conn); ok {
+		if _, p, err := net.SplitHostPort(conn.RemoteAddr().String()); err == nil {
+			port = p
+		}
+	}
+	return net.JoinHostPort(c.forwarded.ClientIP, port)
+}
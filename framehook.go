@@ -0,0 +1,41 @@
+package mcgotocol
+
+import (
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state/states"
+)
+
+// FrameHook observes the uncompressed, unencrypted packet ID and data of every
+// frame read from or written to a connection, together with the direction of
+// the packet and the state it was sent in. data must not be retained after
+// the hook returns. Hooks may be called concurrently from the reading and
+// writing goroutines.
+type FrameHook func(direction proto.Direction, state states.State, data []byte)
+
+// AddFrameHook registers h, e.g. to record traffic.
+func (c *Connection) AddFrameHook(h FrameHook) {
+	c.interceptorMu.Lock()
+	defer c.interceptorMu.Unlock()
+	c.frameHooks = append(c.frameHooks[:len(c.frameHooks):len(c.frameHooks)], h)
+}
+
+func (c *Connection) runFrameHooks(direction proto.Direction, data []byte) {
+	c.interceptorMu.Lock()
+	hooks := c.frameHooks
+	c.interceptorMu.Unlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+	s := c.Registry().State
+	for _, h := range hooks {
+		h(direction, s, data)
+	}
+}
+
+func (c *Connection) outbound() proto.Direction {
+	if c.inbound == proto.ClientBound {
+		return proto.ServerBound
+	}
+	return proto.ClientBound
+}
@@ -0,0 +1,58 @@
+package mcgotocol
+
+import (
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state"
+)
+
+// Interceptor sees every packet together with the state it is read or
+// written in. It returns the packets that take its place: p itself to pass
+// it on (it may be mutated in place), nil to drop it, or several packets to
+// inject additional ones. Each interceptor of a chain sees the output of the
+// previous one. Returning an error aborts the read or write.
+//
+// Inbound interceptors run before auto state acts on the packet, so registry
+// is the state the packet was read in even if it switches the state.
+//
+// Outbound interceptors must not call WritePacket on the same connection.
+type Interceptor func(c *Connection, registry *state.Registry, p proto.Packet) ([]proto.Packet, error)
+
+// AddInboundInterceptor appends i to the chain run on decoded packets before
+// ReadPacket returns them.
+func (c *Connection) AddInboundInterceptor(i Interceptor) {
+	c.interceptorMu.Lock()
+	defer c.interceptorMu.Unlock()
+	// always copy so that chains snapshotted by intercept stay untouched
+	c.inboundInterceptors = append(c.inboundInterceptors[:len(c.inboundInterceptors):len(c.inboundInterceptors)], i)
+}
+
+// AddOutboundInterceptor appends i to the chain run on packets passed to
+// WritePacket before they are encoded.
+func (c *Connection) AddOutboundInterceptor(i Interceptor) {
+	c.interceptorMu.Lock()
+	defer c.interceptorMu.Unlock()
+	c.outboundInterceptors = append(c.outboundInterceptors[:len(c.outboundInterceptors):len(c.outboundInterceptors)], i)
+}
+
+func (c *Connection) intercept(outbound bool, registry *state.Registry, p proto.Packet) ([]proto.Packet, error) {
+	c.interceptorMu.Lock()
+	chain := c.inboundInterceptors
+	if outbound {
+		chain = c.outboundInterceptors
+	}
+	c.interceptorMu.Unlock()
+
+	packets := []proto.Packet{p}
+	for _, interceptor := range chain {
+		var next []proto.Packet
+		for _, pkt := range packets {
+			result, err := interceptor(c, registry, pkt)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, result...)
+		}
+		packets = next
+	}
+	return packets, nil
+}
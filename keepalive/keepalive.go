@@ -0,0 +1,153 @@
+package keepalive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/NaymDev/mcgotocol"
+	"github.com/NaymDev/mcgotocol/chat"
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/google/uuid"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultInterval = 15 * time.Second
+	// DefaultTimeout is the vanilla time after which a client that did not
+	// answer a keep-alive is disconnected.
+	DefaultTimeout = 30 * time.Second
+
+	checkInterval = time.Second
+)
+
+var timeoutReason = codec.NewChat(chat.Translate("disconnect.timeout").Build())
+
+var (
+	ErrUnexpectedID = errors.New("keep-alive with unknown or out-of-order ID")
+	ErrTimeout      = errors.New("keep-alive timed out")
+)
+
+type sentKeepAlive struct {
+	id   codec.VarInt
+	sent time.Time
+}
+
+// Manager sends keep-alives on a Play-state server connection, validates the
+// echoes and measures the round-trip latency.
+type Manager struct {
+	conn     *mcgotocol.Connection
+	Interval time.Duration
+	Timeout  time.Duration
+
+	mu          sync.Mutex
+	outstanding []sentKeepAlive
+	latency     time.Duration
+	measured    bool
+}
+
+func New(conn *mcgotocol.Connection) *Manager {
+	return &Manager{
+		conn:     conn,
+		Interval: DefaultInterval,
+		Timeout:  DefaultTimeout,
+	}
+}
+
+// Run sends a keep-alive every Interval until ctx is done. If the oldest
+// unanswered keep-alive is older than Timeout the client is kicked and
+// ErrTimeout is returned, joined with the error of the kick if it failed.
+// An Interval or Timeout that is not positive means the default.
+func (m *Manager) Run(ctx context.Context) error {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	send := time.NewTicker(interval)
+	defer send.Stop()
+	check := time.NewTicker(min(checkInterval, interval))
+	defer check.Stop()
+
+	if err := m.send(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-send.C:
+			if err := m.send(); err != nil {
+				return err
+			}
+		case <-check.C:
+			if m.timedOut() {
+				if err := m.conn.Kick(timeoutReason); err != nil {
+					return fmt.Errorf("%w, kick failed: %w", ErrTimeout, err)
+				}
+				return ErrTimeout
+			}
+		}
+	}
+}
+
+func (m *Manager) send() error {
+	id := codec.VarInt(rand.Int32())
+
+	m.mu.Lock()
+	m.outstanding = append(m.outstanding, sentKeepAlive{id: id, sent: time.Now()})
+	m.mu.Unlock()
+
+	return m.conn.WritePacket(&packet.ClientKeepAlive{KeepAliveID: id})
+}
+
+func (m *Manager) timedOut() bool {
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.outstanding) > 0 && time.Since(m.outstanding[0].sent) > timeout
+}
+
+// Handle must be called with every ServerKeepAlive read from the connection.
+// The echo has to answer the oldest outstanding keep-alive, anything else
+// fails with ErrUnexpectedID.
+func (m *Manager) Handle(p *packet.ServerKeepAlive) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.outstanding) == 0 || m.outstanding[0].id != p.KeepAliveID {
+		return ErrUnexpectedID
+	}
+	rtt := time.Since(m.outstanding[0].sent)
+	m.outstanding = m.outstanding[1:]
+
+	// same smoothing as the vanilla server
+	if m.measured {
+		m.latency = (m.latency*3 + rtt) / 4
+	} else {
+		m.latency = rtt
+		m.measured = true
+	}
+	return nil
+}
+
+// Latency returns the smoothed round-trip time, zero until the first echo.
+func (m *Manager) Latency() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.latency
+}
+
+// LatencyEntry returns the player list entry for a ClientPlayerListItem with
+// the UpdateLatency action.
+func (m *Manager) LatencyEntry(player uuid.UUID) packet.PlayerProfile {
+	return packet.PlayerProfile{
+		UUID: player,
+		Ping: codec.VarInt(m.Latency().Milliseconds()),
+	}
+}
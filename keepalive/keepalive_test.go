@@ -0,0 +1,180 @@
+package keepalive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/NaymDev/mcgotocol"
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/state"
+	"github.com/google/uuid"
+)
+
+func TestMain(m *testing.M) {
+	state.InitRegistries()
+	os.Exit(m.Run())
+}
+
+// pipe returns the server and client ends of a Play-state connection.
+func pipe(t *testing.T) (server, client *mcgotocol.Connection) {
+	t.Helper()
+	s, c := net.Pipe()
+	t.Cleanup(func() {
+		s.Close()
+		c.Close()
+	})
+	return mcgotocol.NewConnection(s, state.Play), mcgotocol.NewClientConnection(c, state.Play)
+}
+
+func TestEcho(t *testing.T) {
+	server, client := pipe(t)
+	m := New(server)
+	m.Interval = 10 * time.Millisecond
+	m.Timeout = time.Minute
+
+	// the client echoes every keep-alive after a short delay
+	go func() {
+		for {
+			p, err := client.ReadPacket()
+			if err != nil {
+				return
+			}
+			if k, ok := p.(*packet.ClientKeepAlive); ok {
+				time.Sleep(2 * time.Millisecond)
+				if client.WritePacket(&packet.ServerKeepAlive{KeepAliveID: k.KeepAliveID}) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	echoes := make(chan error, 1)
+	go func() {
+		for n := 0; n < 3; n++ {
+			p, err := server.ReadPacket()
+			if err != nil {
+				echoes <- err
+				return
+			}
+			if err := m.Handle(p.(*packet.ServerKeepAlive)); err != nil {
+				echoes <- err
+				return
+			}
+		}
+		echoes <- nil
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+
+	if err := <-echoes; err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+	if l := m.Latency(); l < 2*time.Millisecond {
+		t.Errorf("Latency = %v, want at least the echo delay", l)
+	}
+	if ping := m.LatencyEntry(uuid.Nil).Ping; ping != codec.VarInt(m.Latency().Milliseconds()) {
+		t.Errorf("LatencyEntry ping = %d", ping)
+	}
+}
+
+func TestHandleUnexpectedID(t *testing.T) {
+	server, client := pipe(t)
+	go func() {
+		for {
+			if _, err := client.ReadPacket(); err != nil {
+				return
+			}
+		}
+	}()
+	m := New(server)
+	if err := m.Handle(&packet.ServerKeepAlive{KeepAliveID: 1}); !errors.Is(err, ErrUnexpectedID) {
+		t.Errorf("Handle without keep-alive = %v", err)
+	}
+	if err := m.send(); err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	id := m.outstanding[0].id
+	m.mu.Unlock()
+	if err := m.Handle(&packet.ServerKeepAlive{KeepAliveID: id + 1}); !errors.Is(err, ErrUnexpectedID) {
+		t.Errorf("Handle with wrong ID = %v", err)
+	}
+	if err := m.Handle(&packet.ServerKeepAlive{KeepAliveID: id}); err != nil {
+		t.Errorf("Handle = %v", err)
+	}
+}
+
+func TestTimeoutKick(t *testing.T) {
+	server, client := pipe(t)
+	m := New(server)
+	m.Interval = 10 * time.Millisecond
+	m.Timeout = 50 * time.Millisecond
+
+	// the client reads but never answers
+	reason := make(chan error, 1)
+	go func() {
+		for {
+			if _, err := client.ReadPacket(); err != nil {
+				reason <- err
+				return
+			}
+		}
+	}()
+
+	if err := m.Run(context.Background()); err != ErrTimeout {
+		t.Fatalf("Run = %v, want ErrTimeout", err)
+	}
+	var disconnect *mcgotocol.DisconnectError
+	if err := <-reason; !errors.As(err, &disconnect) {
+		t.Fatalf("client read %v, want a disconnect", err)
+	}
+	if c, _ := disconnect.Reason.Component(); c.Translate != "disconnect.timeout" {
+		t.Errorf("reason = %s", disconnect.Reason)
+	}
+}
+
+func TestTimeoutKickFails(t *testing.T) {
+	server, client := pipe(t)
+	m := New(server)
+	m.Interval = time.Hour
+	m.Timeout = time.Millisecond
+
+	// the client goes away after the first keep-alive
+	go func() {
+		client.ReadPacket()
+		client.Close()
+	}()
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Run = %v, want ErrTimeout and the kick error", err)
+	}
+}
+
+func TestDefaultInterval(t *testing.T) {
+	server, client := pipe(t)
+	m := New(server)
+	m.Interval = 0
+	m.Timeout = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		client.ReadPacket()
+		cancel()
+	}()
+	if err := m.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+}
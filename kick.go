@@ -0,0 +1,57 @@
+package mcgotocol
+
+import (
+	"fmt"
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state/states"
+)
+
+// DisconnectError is returned by ReadPacket on the client side when the
+// server sent a disconnect packet.
+type DisconnectError struct {
+	Reason codec.Chat
+}
+
+var _ error = (*DisconnectError)(nil)
+
+func (e *DisconnectError) Error() string {
+	return fmt.Sprintf("disconnected by server: %s", e.Reason)
+}
+
+// Kick sends the disconnect packet of the current state (Login or Play),
+// flushes it and closes the connection. On the client side and in states
+// without a disconnect packet the connection is only closed.
+func (c *Connection) Kick(reason codec.Chat) error {
+	var p proto.Packet
+	if !c.IsClient() {
+		switch c.Registry().State {
+		case states.LoginState:
+			p = &packet.ClientLoginDisconnect{Reason: reason}
+		case states.PlayState:
+			p = &packet.ClientDisconnect{Reason: reason}
+		}
+	}
+
+	var err error
+	if p != nil {
+		if err = c.WritePacket(p); err == nil {
+			err = c.Flush()
+		}
+	}
+	if closeErr := c.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func disconnectError(p proto.Packet) error {
+	switch p := p.(type) {
+	case *packet.ClientLoginDisconnect:
+		return &DisconnectError{Reason: p.Reason}
+	case *packet.ClientDisconnect:
+		return &DisconnectError{Reason: p.Reason}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package mcgotocol
+
+import (
+	"context"
+	"errors"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state/states"
+	"io"
+	"os"
+	"time"
+)
+
+// legacyPingWait bounds how long readLegacyPing waits for the bytes that tell
+// the ping versions apart. Beta clients send a lone 0xFE and 1.4 clients
+// 0xFE 0x01, so missing bytes are only known to be missing after a while.
+const legacyPingWait = 500 * time.Millisecond
+
+// readLegacyPing checks whether the connection starts with a pre-Netty
+// server list ping. It peeks up to three bytes to tell the ping versions
+// apart from a regular frame whose length happens to start with 0xFE.
+func (c *Connection) readLegacyPing(ctx context.Context) (proto.Packet, bool, error) {
+	first, err := c.reader.Peek(1)
+	if err != nil || first[0] != packet.LegacyPingID {
+		return nil, false, nil
+	}
+
+	peek, err := c.peekLegacy(ctx, 3)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case len(peek) == 1:
+		c.reader.Discard(1)
+		return &packet.LegacyServerListPing{Version: packet.LegacyPingBeta}, true, nil
+	case peek[1] != 0x01:
+		return nil, false, nil
+	case len(peek) == 2:
+		c.reader.Discard(2)
+		return &packet.LegacyServerListPing{Version: packet.LegacyPing14}, true, nil
+	case peek[2] == 0xFA:
+		c.reader.Discard(1)
+		ping := &packet.LegacyServerListPing{}
+		if err := ping.Decode(c.reader); err != nil {
+			return nil, true, err
+		}
+		return ping, true, nil
+	}
+	return nil, false, nil
+}
+
+// peekLegacy peeks n bytes, waiting at most legacyPingWait for those that did
+// not arrive yet. It returns fewer bytes if the client sent no more in time.
+// Connections without deadlines cannot wait without risking to block on a
+// Beta ping forever, so only the buffered bytes are used for them.
+func (c *Connection) peekLegacy(ctx context.Context, n int) ([]byte, error) {
+	d, ok := c.conn.(deadliner)
+	if !ok || c.reader.Buffered() >= n {
+		return c.reader.Peek(min(n, c.reader.Buffered()))
+	}
+
+	wait := time.Now().Add(legacyPingWait)
+	if !c.readDeadline.IsZero() && c.readDeadline.Before(wait) {
+		wait = c.readDeadline
+	}
+	d.SetReadDeadline(wait)
+	peek, err := c.reader.Peek(n)
+	d.SetReadDeadline(c.readDeadline)
+	if ctx.Err() != nil {
+		// the cancellation may have been overwritten above, interrupt the
+		// next read again
+		d.SetReadDeadline(aLongTimeAgo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case err == nil, errors.Is(err, io.EOF):
+		return peek, nil
+	case errors.Is(err, os.ErrDeadlineExceeded) && !wait.Equal(c.readDeadline):
+		return peek, nil
+	}
+	return nil, err
+}
+
+// WriteLegacyPong answers a legacy ping returned by ReadPacket. The
+// connection should be closed afterwards.
+func (c *Connection) WriteLegacyPong(ping *packet.LegacyServerListPing, pong *packet.LegacyServerListPong) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.flushLocked(); err != nil {
+		return err
+	}
+	return pong.Encode(c.writer, ping.Version)
+}
+
+func (c *Connection) expectsLegacyPing() bool {
+	return !c.IsClient() && !c.framesRead && c.Registry().State == states.HandshakeState
+}
@@ -0,0 +1,64 @@
+package mcgotocol
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/state"
+)
+
+func TestReadLegacyPingSplit(t *testing.T) {
+	state.InitRegistries()
+	ping16 := &packet.LegacyServerListPing{ProtocolVersion: 78, Hostname: "localhost", Port: 25565}
+	raw16 := encodeLegacyPing(t, ping16)
+
+	tests := []struct {
+		name   string
+		writes [][]byte
+		want   packet.LegacyPingVersion
+	}{
+		{"beta", [][]byte{{0xFE}}, packet.LegacyPingBeta},
+		{"1.4", [][]byte{{0xFE, 0x01}}, packet.LegacyPing14},
+		{"1.4 split", [][]byte{{0xFE}, {0x01}}, packet.LegacyPing14},
+		{"1.6", [][]byte{raw16}, packet.LegacyPing16},
+		{"1.6 split", [][]byte{raw16[:1], raw16[1:2], raw16[2:]}, packet.LegacyPing16},
+	}
+	for _, tt := range tests {
+		server, client := net.Pipe()
+		go func() {
+			for _, w := range tt.writes {
+				client.Write(w)
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+
+		c := NewConnection(server, state.Handshake)
+		p, err := c.ReadPacket()
+		server.Close()
+		client.Close()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		ping, ok := p.(*packet.LegacyServerListPing)
+		if !ok || ping.Version != tt.want {
+			t.Errorf("%s: ReadPacket = %#v, want version %d", tt.name, p, tt.want)
+			continue
+		}
+		if tt.want == packet.LegacyPing16 && (ping.Hostname != "localhost" || ping.Port != 25565) {
+			t.Errorf("%s: ping = %+v", tt.name, ping)
+		}
+	}
+}
+
+func encodeLegacyPing(t *testing.T, p *packet.LegacyServerListPing) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := p.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,60 @@
+package nbt
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// Compression of NBT files: level.dat and player data are gzipped, region
+// file chunks zlib compressed, network NBT is raw.
+type Compression uint8
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZlib
+)
+
+// Decompress detects the compression of r from its first bytes and returns
+// a reader for the raw NBT.
+func Decompress(r io.Reader) (io.Reader, Compression, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(2)
+	if err != nil && len(head) == 0 {
+		return nil, CompressionNone, err
+	}
+	switch {
+	case len(head) == 2 && head[0] == 0x1F && head[1] == 0x8B:
+		zr, err := gzip.NewReader(br)
+		return zr, CompressionGzip, err
+	case len(head) == 2 && head[0] == 0x78 && (uint16(head[0])<<8|uint16(head[1]))%31 == 0:
+		zr, err := zlib.NewReader(br)
+		return zr, CompressionZlib, err
+	}
+	return br, CompressionNone, nil
+}
+
+// NewCompressedWriter wraps w according to c. The returned writer must be
+// closed to flush the compressed stream; closing does not close w.
+func NewCompressedWriter(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressionNone:
+		return nopCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZlib:
+		return zlib.NewWriter(w), nil
+	}
+	return nil, fmt.Errorf("nbt: unknown compression %d", c)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,303 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// DefaultMaxDepth is the vanilla nesting limit.
+	DefaultMaxDepth = 512
+	// DefaultMaxSize is the vanilla limit for NBT received over the network.
+	DefaultMaxSize = 2 * 1024 * 1024
+)
+
+var (
+	ErrMaxDepth       = errors.New("nbt: maximum nesting depth exceeded")
+	ErrMaxSize        = errors.New("nbt: maximum size exceeded")
+	ErrInvalidTagType = errors.New("nbt: invalid tag type")
+	ErrInvalidLength  = errors.New("nbt: invalid length")
+)
+
+// Decoder reads NBT from a stream. It never reads past the end of the root
+// tag, so it can be used directly on packet data.
+type Decoder struct {
+	r io.Reader
+
+	// MaxDepth limits nesting of lists and compounds, MaxSize the number of
+	// bytes read for one root tag. Zero disables a limit.
+	MaxDepth int
+	MaxSize  int64
+	// Unnamed roots have no name after the type byte (network NBT of later
+	// protocol versions).
+	Unnamed bool
+
+	read int64
+	buf  [8]byte
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:        r,
+		MaxDepth: DefaultMaxDepth,
+		MaxSize:  DefaultMaxSize,
+	}
+}
+
+// ReadRoot reads a root tag. A root consisting of a single TAG_End (used by
+// the protocol for "no NBT") returns a nil tag.
+func (d *Decoder) ReadRoot() (string, Tag, error) {
+	d.read = 0
+	typ, err := d.readType()
+	if err != nil {
+		return "", nil, err
+	}
+	if typ == TagEnd {
+		return "", nil, nil
+	}
+
+	var name string
+	if !d.Unnamed {
+		if name, err = d.readString(); err != nil {
+			return "", nil, err
+		}
+	}
+	tag, err := d.readPayload(typ, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, tag, nil
+}
+
+// Decode reads a root tag and stores it in v, see Unmarshal.
+func (d *Decoder) Decode(v any) error {
+	_, tag, err := d.ReadRoot()
+	if err != nil {
+		return err
+	}
+	if tag == nil {
+		return nil
+	}
+	return FromTag(tag, v)
+}
+
+func (d *Decoder) readFull(n int64) ([]byte, error) {
+	if n < 0 {
+		return nil, ErrInvalidLength
+	}
+	if err := d.account(n); err != nil {
+		return nil, err
+	}
+	var b []byte
+	if n <= int64(len(d.buf)) {
+		b = d.buf[:n]
+	} else {
+		b = make([]byte, n)
+	}
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// account checks the size limit before anything is allocated for n bytes.
+func (d *Decoder) account(n int64) error {
+	d.read += n
+	if d.MaxSize > 0 && d.read > d.MaxSize {
+		return ErrMaxSize
+	}
+	return nil
+}
+
+func (d *Decoder) readType() (TagType, error) {
+	b, err := d.readFull(1)
+	if err != nil {
+		return 0, err
+	}
+	t := TagType(b[0])
+	if t > TagLongArray {
+		return 0, fmt.Errorf("%w %d", ErrInvalidTagType, b[0])
+	}
+	return t, nil
+}
+
+func (d *Decoder) readString() (string, error) {
+	b, err := d.readFull(2)
+	if err != nil {
+		return "", err
+	}
+	b, err = d.readFull(int64(binary.BigEndian.Uint16(b)))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (d *Decoder) readLength() (int64, error) {
+	b, err := d.readFull(4)
+	if err != nil {
+		return 0, err
+	}
+	n := int64(int32(binary.BigEndian.Uint32(b)))
+	if n < 0 {
+		return 0, ErrInvalidLength
+	}
+	return n, nil
+}
+
+func (d *Decoder) readPayload(typ TagType, depth int) (Tag, error) {
+	switch typ {
+	case TagByte:
+		b, err := d.readFull(1)
+		if err != nil {
+			return nil, err
+		}
+		return Byte(b[0]), nil
+	case TagShort:
+		b, err := d.readFull(2)
+		if err != nil {
+			return nil, err
+		}
+		return Short(binary.BigEndian.Uint16(b)), nil
+	case TagInt:
+		b, err := d.readFull(4)
+		if err != nil {
+			return nil, err
+		}
+		return Int(binary.BigEndian.Uint32(b)), nil
+	case TagLong:
+		b, err := d.readFull(8)
+		if err != nil {
+			return nil, err
+		}
+		return Long(binary.BigEndian.Uint64(b)), nil
+	case TagFloat:
+		b, err := d.readFull(4)
+		if err != nil {
+			return nil, err
+		}
+		return Float(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case TagDouble:
+		b, err := d.readFull(8)
+		if err != nil {
+			return nil, err
+		}
+		return Double(math.Float64frombits(binary.BigEndian.Uint64(b))), nil
+	case TagByteArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.readFull(n)
+		if err != nil {
+			return nil, err
+		}
+		return ByteArray(append([]byte(nil), b...)), nil
+	case TagString:
+		s, err := d.readString()
+		return String(s), err
+	case TagList:
+		return d.readList(depth + 1)
+	case TagCompound:
+		return d.readCompound(depth + 1)
+	case TagIntArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.readFull(n * 4)
+		if err != nil {
+			return nil, err
+		}
+		arr := make(IntArray, n)
+		for i := range arr {
+			arr[i] = int32(binary.BigEndian.Uint32(b[i*4:]))
+		}
+		return arr, nil
+	case TagLongArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.readFull(n * 8)
+		if err != nil {
+			return nil, err
+		}
+		arr := make(LongArray, n)
+		for i := range arr {
+			arr[i] = int64(binary.BigEndian.Uint64(b[i*8:]))
+		}
+		return arr, nil
+	}
+	return nil, fmt.Errorf("%w %d", ErrInvalidTagType, byte(typ))
+}
+
+func (d *Decoder) checkDepth(depth int) error {
+	if d.MaxDepth > 0 && depth > d.MaxDepth {
+		return ErrMaxDepth
+	}
+	return nil
+}
+
+func (d *Decoder) readList(depth int) (*List, error) {
+	if err := d.checkDepth(depth); err != nil {
+		return nil, err
+	}
+	elemType, err := d.readType()
+	if err != nil {
+		return nil, err
+	}
+	n, err := d.readLength()
+	if err != nil {
+		return nil, err
+	}
+	if elemType == TagEnd && n > 0 {
+		return nil, fmt.Errorf("%w: non-empty list of TAG_End", ErrInvalidTagType)
+	}
+	// every element occupies at least one byte, reject impossible counts
+	// before allocating
+	if d.MaxSize > 0 && n > d.MaxSize-d.read {
+		return nil, ErrMaxSize
+	}
+
+	list := &List{ElemType: elemType, Elems: make([]Tag, 0, min(n, 1024))}
+	for i := int64(0); i < n; i++ {
+		elem, err := d.readPayload(elemType, depth)
+		if err != nil {
+			return nil, err
+		}
+		list.Elems = append(list.Elems, elem)
+	}
+	return list, nil
+}
+
+func (d *Decoder) readCompound(depth int) (Compound, error) {
+	if err := d.checkDepth(depth); err != nil {
+		return nil, err
+	}
+	c := Compound{}
+	for {
+		typ, err := d.readType()
+		if err != nil {
+			return nil, err
+		}
+		if typ == TagEnd {
+			return c, nil
+		}
+		name, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+		tag, err := d.readPayload(typ, depth)
+		if err != nil {
+			return nil, err
+		}
+		c[name] = tag
+	}
+}
@@ -0,0 +1,192 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+var (
+	ErrMixedList = errors.New("nbt: list elements must have the list's type")
+	ErrNilTag    = errors.New("nbt: nil tag")
+)
+
+// Encoder writes NBT to a stream. Besides whole trees (WriteRoot, Encode) it
+// can write tags piece by piece with WriteHeader, WritePayload,
+// WriteListHeader and WriteEnd, so large structures need not be built in
+// memory first.
+type Encoder struct {
+	w io.Writer
+	// Unnamed writes roots without a name, see Decoder.Unnamed.
+	Unnamed bool
+
+	buf [8]byte
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// WriteRoot writes tag as root. A nil tag is written as a single TAG_End.
+func (e *Encoder) WriteRoot(name string, tag Tag) error {
+	if tag == nil {
+		return e.WriteEnd()
+	}
+	if e.Unnamed {
+		if err := e.writeType(tag.Type()); err != nil {
+			return err
+		}
+	} else if err := e.WriteHeader(tag.Type(), name); err != nil {
+		return err
+	}
+	return e.WritePayload(tag)
+}
+
+// Encode converts v with ToTag and writes it as root with an empty name.
+func (e *Encoder) Encode(v any) error {
+	return e.EncodeNamed("", v)
+}
+
+func (e *Encoder) EncodeNamed(name string, v any) error {
+	tag, err := ToTag(v)
+	if err != nil {
+		return err
+	}
+	return e.WriteRoot(name, tag)
+}
+
+// WriteHeader starts a named tag, e.g. an entry of a compound.
+func (e *Encoder) WriteHeader(typ TagType, name string) error {
+	if err := e.writeType(typ); err != nil {
+		return err
+	}
+	return e.writeString(name)
+}
+
+// WriteEnd closes a compound started with WriteHeader(TagCompound, ...).
+func (e *Encoder) WriteEnd() error {
+	return e.writeType(TagEnd)
+}
+
+// WriteListHeader starts a list whose n elements must follow as payloads.
+func (e *Encoder) WriteListHeader(elemType TagType, n int) error {
+	if err := e.writeType(elemType); err != nil {
+		return err
+	}
+	return e.writeInt(int32(n))
+}
+
+// WritePayload writes the payload of tag without type and name.
+func (e *Encoder) WritePayload(tag Tag) error {
+	switch t := tag.(type) {
+	case Byte:
+		return e.write([]byte{byte(t)})
+	case Short:
+		binary.BigEndian.PutUint16(e.buf[:2], uint16(t))
+		return e.write(e.buf[:2])
+	case Int:
+		return e.writeInt(int32(t))
+	case Long:
+		binary.BigEndian.PutUint64(e.buf[:8], uint64(t))
+		return e.write(e.buf[:8])
+	case Float:
+		binary.BigEndian.PutUint32(e.buf[:4], math.Float32bits(float32(t)))
+		return e.write(e.buf[:4])
+	case Double:
+		binary.BigEndian.PutUint64(e.buf[:8], math.Float64bits(float64(t)))
+		return e.write(e.buf[:8])
+	case ByteArray:
+		if err := e.writeInt(int32(len(t))); err != nil {
+			return err
+		}
+		return e.write(t)
+	case String:
+		return e.writeString(string(t))
+	case *List:
+		if t == nil {
+			return ErrNilTag
+		}
+		if err := e.WriteListHeader(t.ElemType, len(t.Elems)); err != nil {
+			return err
+		}
+		for _, elem := range t.Elems {
+			if elem == nil {
+				return fmt.Errorf("%w in list of %s", ErrNilTag, t.ElemType)
+			}
+			if elem.Type() != t.ElemType {
+				return fmt.Errorf("%w: %s in list of %s", ErrMixedList, elem.Type(), t.ElemType)
+			}
+			if err := e.WritePayload(elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Compound:
+		// sorted so that the output is deterministic
+		names := make([]string, 0, len(t))
+		for name := range t {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if t[name] == nil {
+				return fmt.Errorf("%w at %q", ErrNilTag, name)
+			}
+			if err := e.WriteHeader(t[name].Type(), name); err != nil {
+				return err
+			}
+			if err := e.WritePayload(t[name]); err != nil {
+				return err
+			}
+		}
+		return e.WriteEnd()
+	case IntArray:
+		if err := e.writeInt(int32(len(t))); err != nil {
+			return err
+		}
+		b := make([]byte, 4*len(t))
+		for i, v := range t {
+			binary.BigEndian.PutUint32(b[i*4:], uint32(v))
+		}
+		return e.write(b)
+	case LongArray:
+		if err := e.writeInt(int32(len(t))); err != nil {
+			return err
+		}
+		b := make([]byte, 8*len(t))
+		for i, v := range t {
+			binary.BigEndian.PutUint64(b[i*8:], uint64(v))
+		}
+		return e.write(b)
+	}
+	return fmt.Errorf("%w %T", ErrInvalidTagType, tag)
+}
+
+func (e *Encoder) write(b []byte) error {
+	_, err := e.w.Write(b)
+	return err
+}
+
+func (e *Encoder) writeType(t TagType) error {
+	return e.write([]byte{byte(t)})
+}
+
+func (e *Encoder) writeInt(v int32) error {
+	binary.BigEndian.PutUint32(e.buf[:4], uint32(v))
+	return e.write(e.buf[:4])
+}
+
+func (e *Encoder) writeString(s string) error {
+	if len(s) > math.MaxUint16 {
+		return fmt.Errorf("%w: string of %d bytes", ErrInvalidLength, len(s))
+	}
+	binary.BigEndian.PutUint16(e.buf[:2], uint16(len(s)))
+	if err := e.write(e.buf[:2]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, s)
+	return err
+}
@@ -0,0 +1,423 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var ErrUnsupportedType = errors.New("nbt: unsupported type")
+
+// Marshal encodes v as a root compound with an empty name.
+//
+// Go values map to tags as follows: bool and int8 to TAG_Byte, int16 to
+// TAG_Short, int32 and int to TAG_Int, int64 to TAG_Long, float32 and
+// float64 to TAG_Float and TAG_Double, string to TAG_String, []byte to
+// TAG_Byte_Array, []int32 to TAG_Int_Array, []int64 to TAG_Long_Array, other
+// slices and arrays to TAG_List, structs and map[string]T to TAG_Compound.
+// Tag values are used as they are.
+//
+// Struct fields are named after the `nbt:"name"` tag or the field name.
+// Options after the name: "omitempty" skips zero values, "list" encodes
+// []byte, []int32 and []int64 as TAG_List. A name of "-" skips the field.
+func Marshal(v any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := NewEncoder(buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a root tag from data into the value pointed to by v.
+// Numbers are converted between integer tags and between float tags.
+func Unmarshal(data []byte, v any) error {
+	return NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+var tagInterface = reflect.TypeOf((*Tag)(nil)).Elem()
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	asList    bool
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+func structFields(t reflect.Type) []field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field)
+	}
+	var fields []field
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || sf.Anonymous {
+			continue
+		}
+		f := field{name: sf.Name, index: sf.Index}
+		if tag, ok := sf.Tag.Lookup("nbt"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				f.name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				switch opt {
+				case "omitempty":
+					f.omitEmpty = true
+				case "list":
+					f.asList = true
+				}
+			}
+		}
+		fields = append(fields, f)
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// ToTag converts a Go value into a tag tree, see Marshal.
+func ToTag(v any) (Tag, error) {
+	return toTag(reflect.ValueOf(v), false)
+}
+
+func toTag(v reflect.Value, asList bool) (Tag, error) {
+	if !v.IsValid() {
+		return nil, fmt.Errorf("%w: nil", ErrUnsupportedType)
+	}
+	if v.Type().Implements(tagInterface) && !(v.Kind() == reflect.Interface && v.IsNil()) {
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		return v.Interface().(Tag), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, fmt.Errorf("%w: nil %s", ErrUnsupportedType, v.Type())
+		}
+		return toTag(v.Elem(), asList)
+	case reflect.Bool:
+		if v.Bool() {
+			return Byte(1), nil
+		}
+		return Byte(0), nil
+	case reflect.Int8, reflect.Uint8:
+		return Byte(v.Convert(reflect.TypeOf(int8(0))).Int()), nil
+	case reflect.Int16, reflect.Uint16:
+		return Short(v.Convert(reflect.TypeOf(int16(0))).Int()), nil
+	case reflect.Int32, reflect.Int, reflect.Uint32:
+		return Int(v.Convert(reflect.TypeOf(int32(0))).Int()), nil
+	case reflect.Int64, reflect.Uint64, reflect.Uint:
+		return Long(v.Convert(reflect.TypeOf(int64(0))).Int()), nil
+	case reflect.Float32:
+		return Float(v.Float()), nil
+	case reflect.Float64:
+		return Double(v.Float()), nil
+	case reflect.String:
+		return String(v.String()), nil
+	case reflect.Slice, reflect.Array:
+		if !asList {
+			switch v.Type().Elem().Kind() {
+			case reflect.Uint8, reflect.Int8:
+				b := make(ByteArray, v.Len())
+				for i := range b {
+					b[i] = byte(v.Index(i).Convert(reflect.TypeOf(uint8(0))).Uint())
+				}
+				return b, nil
+			case reflect.Int32:
+				a := make(IntArray, v.Len())
+				for i := range a {
+					a[i] = int32(v.Index(i).Int())
+				}
+				return a, nil
+			case reflect.Int64:
+				a := make(LongArray, v.Len())
+				for i := range a {
+					a[i] = v.Index(i).Int()
+				}
+				return a, nil
+			}
+		}
+		list := &List{ElemType: TagEnd, Elems: make([]Tag, 0, v.Len())}
+		for i := 0; i < v.Len(); i++ {
+			elem, err := toTag(v.Index(i), false)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				list.ElemType = elem.Type()
+			} else if elem.Type() != list.ElemType {
+				return nil, ErrMixedList
+			}
+			list.Elems = append(list.Elems, elem)
+		}
+		if v.Len() == 0 {
+			list.ElemType = emptyListType(v.Type().Elem())
+		}
+		return list, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%w: map key %s", ErrUnsupportedType, v.Type().Key())
+		}
+		c := make(Compound, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			tag, err := toTag(iter.Value(), false)
+			if err != nil {
+				return nil, err
+			}
+			c[iter.Key().String()] = tag
+		}
+		return c, nil
+	case reflect.Struct:
+		c := Compound{}
+		for _, f := range structFields(v.Type()) {
+			fv := v.FieldByIndex(f.index)
+			if f.omitEmpty && fv.IsZero() {
+				continue
+			}
+			if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil() {
+				continue
+			}
+			tag, err := toTag(fv, f.asList)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.name, err)
+			}
+			c[f.name] = tag
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+}
+
+// emptyListType works out the element type of an empty list from the Go
+// type alone, mirroring toTag. It does not build a value, so self-referential
+// types such as struct{ Children []Node } terminate.
+func emptyListType(t reflect.Type) TagType {
+	if t.Kind() != reflect.Interface && t.Implements(tagInterface) {
+		if t.Kind() == reflect.Pointer && t.Elem().Kind() != reflect.Struct {
+			return TagEnd
+		}
+		// the tag types' Type methods do not look at the receiver
+		return reflect.Zero(t).Interface().(Tag).Type()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return emptyListType(t.Elem())
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return TagByte
+	case reflect.Int16, reflect.Uint16:
+		return TagShort
+	case reflect.Int32, reflect.Int, reflect.Uint32:
+		return TagInt
+	case reflect.Int64, reflect.Uint64, reflect.Uint:
+		return TagLong
+	case reflect.Float32:
+		return TagFloat
+	case reflect.Float64:
+		return TagDouble
+	case reflect.String:
+		return TagString
+	case reflect.Slice, reflect.Array:
+		switch t.Elem().Kind() {
+		case reflect.Uint8, reflect.Int8:
+			return TagByteArray
+		case reflect.Int32:
+			return TagIntArray
+		case reflect.Int64:
+			return TagLongArray
+		}
+		return TagList
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return TagCompound
+		}
+	case reflect.Struct:
+		return TagCompound
+	}
+	return TagEnd
+}
+
+// FromTag stores tag in the value pointed to by v, see Unmarshal.
+func FromTag(tag Tag, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: need non-nil pointer, got %T", ErrUnsupportedType, v)
+	}
+	return fromTag(tag, rv.Elem())
+}
+
+func mismatch(tag Tag, v reflect.Value) error {
+	return fmt.Errorf("nbt: cannot store %s in %s", tag.Type(), v.Type())
+}
+
+func fromTag(tag Tag, v reflect.Value) error {
+	// Tag, any and fields of the exact tag type take the tag as it is
+	if reflect.TypeOf(tag).AssignableTo(v.Type()) {
+		v.Set(reflect.ValueOf(tag))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return fromTag(tag, v.Elem())
+	case reflect.Bool:
+		n, ok := tagInt(tag)
+		if !ok {
+			return mismatch(tag, v)
+		}
+		v.SetBool(n != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := tagInt(tag)
+		if !ok || v.OverflowInt(n) {
+			return mismatch(tag, v)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := tagInt(tag)
+		if !ok {
+			return mismatch(tag, v)
+		}
+		u := uint64(n)
+		if n < 0 {
+			// toTag stores unsigned values in the signed tag of the same
+			// size, so only that tag may have the sign bit set
+			bits := tagBits(tag)
+			if bits != v.Type().Bits() {
+				return mismatch(tag, v)
+			}
+			u &= math.MaxUint64 >> (64 - bits)
+		}
+		if v.OverflowUint(u) {
+			return mismatch(tag, v)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		switch t := tag.(type) {
+		case Float:
+			v.SetFloat(float64(t))
+		case Double:
+			v.SetFloat(float64(t))
+		default:
+			return mismatch(tag, v)
+		}
+	case reflect.String:
+		s, ok := tag.(String)
+		if !ok {
+			return mismatch(tag, v)
+		}
+		v.SetString(string(s))
+	case reflect.Slice, reflect.Array:
+		elems, ok := tagElems(tag)
+		if !ok {
+			return mismatch(tag, v)
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(elems), len(elems)))
+		} else if v.Len() != len(elems) {
+			return fmt.Errorf("nbt: cannot store %d elements in %s", len(elems), v.Type())
+		}
+		for i, elem := range elems {
+			if err := fromTag(elem, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		c, ok := tag.(Compound)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return mismatch(tag, v)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(c)))
+		}
+		for name, child := range c {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := fromTag(child, elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), elem)
+		}
+	case reflect.Struct:
+		c, ok := tag.(Compound)
+		if !ok {
+			return mismatch(tag, v)
+		}
+		for _, f := range structFields(v.Type()) {
+			child, ok := c[f.name]
+			if !ok {
+				continue
+			}
+			if err := fromTag(child, v.FieldByIndex(f.index)); err != nil {
+				return fmt.Errorf("field %s: %w", f.name, err)
+			}
+		}
+	default:
+		return mismatch(tag, v)
+	}
+	return nil
+}
+
+func tagInt(tag Tag) (int64, bool) {
+	switch t := tag.(type) {
+	case Byte:
+		return int64(t), true
+	case Short:
+		return int64(t), true
+	case Int:
+		return int64(t), true
+	case Long:
+		return int64(t), true
+	}
+	return 0, false
+}
+
+func tagBits(tag Tag) int {
+	switch tag.(type) {
+	case Byte:
+		return 8
+	case Short:
+		return 16
+	case Int:
+		return 32
+	}
+	return 64
+}
+
+// tagElems returns the elements of lists and arrays as tags.
+func tagElems(tag Tag) ([]Tag, bool) {
+	switch t := tag.(type) {
+	case *List:
+		return t.Elems, true
+	case ByteArray:
+		elems := make([]Tag, len(t))
+		for i, b := range t {
+			elems[i] = Byte(b)
+		}
+		return elems, true
+	case IntArray:
+		elems := make([]Tag, len(t))
+		for i, n := range t {
+			elems[i] = Int(n)
+		}
+		return elems, true
+	case LongArray:
+		elems := make([]Tag, len(t))
+		for i, n := range t {
+			elems[i] = Long(n)
+		}
+		return elems, true
+	}
+	return nil, false
+}
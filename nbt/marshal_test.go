@@ -0,0 +1,110 @@
+package nbt
+
+import (
+	"errors"
+	"testing"
+)
+
+type node struct {
+	Name     string
+	Children []node
+}
+
+func TestMarshalSelfReferentialType(t *testing.T) {
+	tag, err := ToTag(node{Name: "root"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	children := tag.(Compound).List("Children")
+	if children == nil || children.ElemType != TagCompound || len(children.Elems) != 0 {
+		t.Fatalf("Children = %#v, want empty TAG_Compound list", children)
+	}
+
+	in := node{Name: "a", Children: []node{{Name: "b"}, {Name: "c", Children: []node{{Name: "d"}}}}}
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out node
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "a" || len(out.Children) != 2 || out.Children[1].Children[0].Name != "d" {
+		t.Fatalf("round trip = %+v", out)
+	}
+}
+
+func TestEmptyListType(t *testing.T) {
+	type ptrNode struct {
+		Next []*ptrNode
+	}
+	tests := []struct {
+		v    any
+		want TagType
+	}{
+		{[]int16{}, TagShort},
+		{[]string{}, TagString},
+		{[][]byte{}, TagByteArray},
+		{[][]string{}, TagList},
+		{[]map[string]int{}, TagCompound},
+		{[]Tag{}, TagEnd},
+		{[]*List{}, TagList},
+		{[]Float{}, TagFloat},
+		{[]*ptrNode{}, TagCompound},
+	}
+	for _, tt := range tests {
+		tag, err := ToTag(tt.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tag.(*List).ElemType; got != tt.want {
+			t.Errorf("%T: element type %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestFromTagUnsignedRange(t *testing.T) {
+	var u8 uint8
+	if err := FromTag(Byte(-56), &u8); err != nil || u8 != 200 {
+		t.Errorf("Byte(-56) into uint8 = %d, %v; want 200", u8, err)
+	}
+	if err := FromTag(Int(300), &u8); err == nil {
+		t.Error("Int(300) into uint8 did not fail")
+	}
+	var u16 uint16
+	if err := FromTag(Byte(-1), &u16); err == nil {
+		t.Error("Byte(-1) into uint16 did not fail")
+	}
+	var u32 uint32
+	if err := FromTag(Long(-1), &u32); err == nil {
+		t.Error("Long(-1) into uint32 did not fail")
+	}
+	if err := FromTag(Long(1<<40), &u32); err == nil {
+		t.Error("Long(1<<40) into uint32 did not fail")
+	}
+
+	type unsigned struct {
+		A uint8
+		B uint16
+		C uint32
+		D uint64
+	}
+	in := unsigned{A: 255, B: 65535, C: 1<<32 - 1, D: 1<<64 - 1}
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out unsigned
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestUnsupportedMapKey(t *testing.T) {
+	if _, err := ToTag(map[int]int{1: 1}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("err = %v, want ErrUnsupportedType", err)
+	}
+}
@@ -0,0 +1,146 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// hello_world.nbt from the NBT specification
+var helloWorld = []byte{
+	0x0a, 0x00, 0x0b, 'h', 'e', 'l', 'l', 'o', ' ', 'w', 'o', 'r', 'l', 'd',
+	0x08, 0x00, 0x04, 'n', 'a', 'm', 'e', 0x00, 0x09, 'B', 'a', 'n', 'a', 'n', 'r', 'a', 'm', 'a',
+	0x00,
+}
+
+func TestHelloWorld(t *testing.T) {
+	name, tag, err := NewDecoder(bytes.NewReader(helloWorld)).ReadRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Compound{"name": String("Bananrama")}
+	if name != "hello world" || !reflect.DeepEqual(tag, want) {
+		t.Fatalf("ReadRoot = %q, %#v", name, tag)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := NewEncoder(buf).WriteRoot(name, tag); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), helloWorld) {
+		t.Fatalf("WriteRoot = % x", buf.Bytes())
+	}
+
+	var v struct {
+		Name string `nbt:"name"`
+	}
+	if err := Unmarshal(helloWorld, &v); err != nil || v.Name != "Bananrama" {
+		t.Fatalf("Unmarshal = %+v, %v", v, err)
+	}
+}
+
+// every tag type once, entries in the sorted order the encoder writes
+var allTags = bytes.Join([][]byte{
+	{0x0a, 0x00, 0x00},
+	{0x01, 0x00, 0x01, 'b', 0x01},
+	{0x07, 0x00, 0x02, 'b', 'a', 0x00, 0x00, 0x00, 0x02, 0x01, 0x02},
+	{0x06, 0x00, 0x01, 'd', 0x3f, 0xe0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	{0x05, 0x00, 0x01, 'f', 0x3f, 0x00, 0x00, 0x00},
+	{0x03, 0x00, 0x01, 'i', 0xff, 0xff, 0xff, 0xfe},
+	{0x0b, 0x00, 0x02, 'i', 'a', 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01},
+	{0x04, 0x00, 0x01, 'l', 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00},
+	{0x0c, 0x00, 0x02, 'l', 'a', 0x00, 0x00, 0x00, 0x00},
+	{0x09, 0x00, 0x02, 'l', 'i', 0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x03},
+	{0x09, 0x00, 0x01, 'n', 0x00, 0x00, 0x00, 0x00, 0x00},
+	{0x08, 0x00, 0x01, 's', 0x00, 0x03, 0xc2, 0xa7, 'a'},
+	{0x02, 0x00, 0x02, 's', 'h', 0xff, 0xff},
+	{0x00},
+}, nil)
+
+func TestAllTags(t *testing.T) {
+	want := Compound{
+		"b":  Byte(1),
+		"ba": ByteArray{1, 2},
+		"d":  Double(0.5),
+		"f":  Float(0.5),
+		"i":  Int(-2),
+		"ia": IntArray{1},
+		"l":  Long(1 << 40),
+		"la": LongArray{},
+		"li": &List{ElemType: TagShort, Elems: []Tag{Short(3)}},
+		"n":  &List{ElemType: TagEnd, Elems: []Tag{}},
+		"s":  String("§a"),
+		"sh": Short(-1),
+	}
+
+	_, tag, err := NewDecoder(bytes.NewReader(allTags)).ReadRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tag, want) {
+		t.Fatalf("ReadRoot = %#v", tag)
+	}
+	buf := &bytes.Buffer{}
+	if err := NewEncoder(buf).WriteRoot("", want); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), allTags) {
+		t.Fatalf("WriteRoot = % x\nwant      % x", buf.Bytes(), allTags)
+	}
+}
+
+func TestDecoderRejects(t *testing.T) {
+	nested := []byte{0x09, 0x00, 0x00}
+	for i := 0; i < 4; i++ {
+		nested = append(nested, 0x09, 0x00, 0x00, 0x00, 0x01)
+	}
+	nested = append(nested, 0x00, 0x00, 0x00, 0x00, 0x00)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		maxDepth int
+		want     error
+	}{
+		{"depth", nested, 3, ErrMaxDepth},
+		{"negative length", []byte{0x07, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff}, 0, ErrInvalidLength},
+		{"list of end", []byte{0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, 0, ErrInvalidTagType},
+		{"unknown type", []byte{0x0d, 0x00, 0x00}, 0, ErrInvalidTagType},
+		{"oversized list", []byte{0x09, 0x00, 0x00, 0x01, 0x7f, 0xff, 0xff, 0xff}, 0, ErrMaxSize},
+	}
+	for _, tt := range tests {
+		d := NewDecoder(bytes.NewReader(tt.data))
+		if tt.maxDepth > 0 {
+			d.MaxDepth = tt.maxDepth
+		}
+		if _, _, err := d.ReadRoot(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	d := NewDecoder(bytes.NewReader(nested))
+	if _, _, err := d.ReadRoot(); err != nil {
+		t.Errorf("nesting within the default depth: %v", err)
+	}
+}
+
+func TestEncoderRejects(t *testing.T) {
+	var nilList *List
+	tests := []struct {
+		name string
+		tag  Tag
+		want error
+	}{
+		{"nil compound value", Compound{"x": nil}, ErrNilTag},
+		{"nil list element", &List{ElemType: TagInt, Elems: []Tag{Int(1), nil}}, ErrNilTag},
+		{"nil list", Compound{"l": nilList}, ErrNilTag},
+		{"nested nil", Compound{"c": Compound{"x": nil}}, ErrNilTag},
+		{"mixed list", &List{ElemType: TagInt, Elems: []Tag{Short(1)}}, ErrMixedList},
+	}
+	for _, tt := range tests {
+		if err := NewEncoder(&bytes.Buffer{}).WriteRoot("", tt.tag); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
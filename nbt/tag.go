@@ -0,0 +1,141 @@
+package nbt
+
+import "fmt"
+
+type TagType byte
+
+const (
+	TagEnd TagType = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+func (t TagType) String() string {
+	switch t {
+	case TagEnd:
+		return "TAG_End"
+	case TagByte:
+		return "TAG_Byte"
+	case TagShort:
+		return "TAG_Short"
+	case TagInt:
+		return "TAG_Int"
+	case TagLong:
+		return "TAG_Long"
+	case TagFloat:
+		return "TAG_Float"
+	case TagDouble:
+		return "TAG_Double"
+	case TagByteArray:
+		return "TAG_Byte_Array"
+	case TagString:
+		return "TAG_String"
+	case TagList:
+		return "TAG_List"
+	case TagCompound:
+		return "TAG_Compound"
+	case TagIntArray:
+		return "TAG_Int_Array"
+	case TagLongArray:
+		return "TAG_Long_Array"
+	}
+	return fmt.Sprintf("TAG_Unknown(%d)", byte(t))
+}
+
+var _ fmt.Stringer = (*TagType)(nil)
+
+// Tag is a node of the dynamic NBT tree.
+type Tag interface {
+	Type() TagType
+}
+
+type (
+	Byte      int8
+	Short     int16
+	Int       int32
+	Long      int64
+	Float     float32
+	Double    float64
+	ByteArray []byte
+	String    string
+	IntArray  []int32
+	LongArray []int64
+)
+
+// List holds tags of a single type. ElemType is kept for empty lists, whose
+// element type is still part of the encoding.
+type List struct {
+	ElemType TagType
+	Elems    []Tag
+}
+
+// Compound maps names to tags. The order of entries is not preserved.
+type Compound map[string]Tag
+
+func (Byte) Type() TagType      { return TagByte }
+func (Short) Type() TagType     { return TagShort }
+func (Int) Type() TagType       { return TagInt }
+func (Long) Type() TagType      { return TagLong }
+func (Float) Type() TagType     { return TagFloat }
+func (Double) Type() TagType    { return TagDouble }
+func (ByteArray) Type() TagType { return TagByteArray }
+func (String) Type() TagType    { return TagString }
+func (*List) Type() TagType     { return TagList }
+func (Compound) Type() TagType  { return TagCompound }
+func (IntArray) Type() TagType  { return TagIntArray }
+func (LongArray) Type() TagType { return TagLongArray }
+
+// Compound returns the compound stored under name, or nil.
+func (c Compound) Compound(name string) Compound {
+	v, _ := c[name].(Compound)
+	return v
+}
+
+// List returns the list stored under name, or nil.
+func (c Compound) List(name string) *List {
+	v, _ := c[name].(*List)
+	return v
+}
+
+func (c Compound) String(name string) (string, bool) {
+	v, ok := c[name].(String)
+	return string(v), ok
+}
+
+// Int returns the integer stored under name. Byte, Short, Int and Long tags
+// are all accepted.
+func (c Compound) Int(name string) (int64, bool) {
+	switch v := c[name].(type) {
+	case Byte:
+		return int64(v), true
+	case Short:
+		return int64(v), true
+	case Int:
+		return int64(v), true
+	case Long:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// Float returns the number stored under name as float64. Float and Double
+// tags are accepted.
+func (c Compound) Float(name string) (float64, bool) {
+	switch v := c[name].(type) {
+	case Float:
+		return float64(v), true
+	case Double:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,93 @@
+package packet
+
+import (
+	"fmt"
+	"github.com/NaymDev/mcgotocol/codec"
+)
+
+// NewClientChunkData sends the non-empty sections of col. With groundUp the
+// client replaces the whole column, including biomes; otherwise it only
+// updates the sent sections. skyLight must be true in dimensions with a sky.
+//
+// A ground-up column without blocks is sent with its lowest section, empty,
+// because a 1.8 client unloads the column for an empty bit mask (see
+// IsUnload). Use NewClientUnloadChunk to unload it.
+func NewClientChunkData(col *codec.ChunkColumn, skyLight, groundUp bool) *ClientChunkData {
+	mask := col.PrimaryBitMask()
+	if groundUp && mask == 0 {
+		mask = 1
+	}
+	return &ClientChunkData{
+		ChunkX:         col.X,
+		ChunkZ:         col.Z,
+		GroundUp:       groundUp,
+		PrimaryBitMask: mask,
+		Data:           col.AppendData(nil, mask, skyLight, groundUp),
+	}
+}
+
+// NewClientUnloadChunk tells the client to forget the column at x, z.
+func NewClientUnloadChunk(x, z int32) *ClientChunkData {
+	return &ClientChunkData{
+		ChunkX:   x,
+		ChunkZ:   z,
+		GroundUp: true,
+		Data:     []byte{},
+	}
+}
+
+// IsUnload reports whether c unloads its column: ground-up with an empty bit
+// mask. The data is ignored; vanilla still sends the 256 biome bytes while
+// NewClientUnloadChunk sends none.
+func (c *ClientChunkData) IsUnload() bool {
+	return c.GroundUp && c.PrimaryBitMask == 0
+}
+
+// Column decodes the sections carried by c. Sections outside the bit mask
+// are nil.
+func (c *ClientChunkData) Column(skyLight bool) (*codec.ChunkColumn, error) {
+	col := &codec.ChunkColumn{X: c.ChunkX, Z: c.ChunkZ}
+	if c.IsUnload() {
+		return col, nil
+	}
+	if err := col.ReadData(c.Data, c.PrimaryBitMask, skyLight, c.GroundUp); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// NewClientMapChunkBulk sends several full columns at once.
+func NewClientMapChunkBulk(cols []*codec.ChunkColumn, skyLight bool) *ClientMapChunkBulk {
+	bulk := &ClientMapChunkBulk{
+		SkyLightSent: skyLight,
+		Meta:         make([]ClientMapChunkBulkMeta, len(cols)),
+	}
+	for i, col := range cols {
+		mask := col.PrimaryBitMask()
+		bulk.Meta[i] = ClientMapChunkBulkMeta{ChunkX: col.X, ChunkZ: col.Z, PrimaryBitMask: mask}
+		bulk.Data = col.AppendData(bulk.Data, mask, skyLight, true)
+	}
+	return bulk
+}
+
+// Columns decodes every column in c.
+func (c *ClientMapChunkBulk) Columns() ([]*codec.ChunkColumn, error) {
+	cols := make([]*codec.ChunkColumn, len(c.Meta))
+	data := c.Data
+	for i, meta := range c.Meta {
+		size := codec.ChunkDataSize(meta.PrimaryBitMask, c.SkyLightSent, true)
+		if size > len(data) {
+			return nil, fmt.Errorf("%w: column %d needs %d bytes, %d left", codec.ErrChunkDataSize, i, size, len(data))
+		}
+		col := &codec.ChunkColumn{X: meta.ChunkX, Z: meta.ChunkZ}
+		if err := col.ReadData(data[:size], meta.PrimaryBitMask, c.SkyLightSent, true); err != nil {
+			return nil, err
+		}
+		cols[i] = col
+		data = data[size:]
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", codec.ErrChunkDataSize, len(data))
+	}
+	return cols, nil
+}
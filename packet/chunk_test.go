@@ -0,0 +1,88 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NaymDev/mcgotocol/codec"
+)
+
+func TestIsUnload(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *ClientChunkData
+		want bool
+	}{
+		{"NewClientUnloadChunk", NewClientUnloadChunk(3, -4), true},
+		// vanilla writes the biomes even for an empty ground-up column
+		{"vanilla", &ClientChunkData{GroundUp: true, Data: make([]byte, codec.BiomesPerChunk)}, true},
+		{"not ground-up", &ClientChunkData{Data: []byte{}}, false},
+		{"sections", &ClientChunkData{GroundUp: true, PrimaryBitMask: 1}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.p.IsUnload(); got != tt.want {
+			t.Errorf("%s: IsUnload = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	col, err := tests[1].p.Column(true)
+	if err != nil {
+		t.Fatalf("Column of a vanilla unload: %v", err)
+	}
+	if col.PrimaryBitMask() != 0 {
+		t.Fatal("Column of an unload has sections")
+	}
+}
+
+func TestChunkDataEmptyColumn(t *testing.T) {
+	col := &codec.ChunkColumn{X: 3, Z: -4}
+	col.Biomes[0] = 2
+	p := NewClientChunkData(col, true, true)
+	if p.IsUnload() {
+		t.Fatal("an empty ground-up column is sent as an unload")
+	}
+	got, err := p.Column(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Biomes[0] != 2 || got.Block(0, 0, 0) != 0 {
+		t.Errorf("Column = biome %d, block %#x", got.Biomes[0], got.Block(0, 0, 0))
+	}
+
+	// sections that are not ground-up need no placeholder
+	if p := NewClientChunkData(col, true, false); p.PrimaryBitMask != 0 || len(p.Data) != 0 {
+		t.Errorf("update without sections = mask %#x, %d bytes", p.PrimaryBitMask, len(p.Data))
+	}
+}
+
+func TestMapChunkBulkColumns(t *testing.T) {
+	a := &codec.ChunkColumn{X: 1, Z: 2}
+	a.SetBlock(0, 0, 0, codec.BlockState(1, 0))
+	b := &codec.ChunkColumn{X: -1, Z: 5}
+	b.SetBlock(4, 200, 4, codec.BlockState(89, 0))
+	b.Biomes[0] = 2
+
+	bulk := NewClientMapChunkBulk([]*codec.ChunkColumn{a, b}, true)
+	want := append(a.AppendData(nil, 0x0001, true, true), b.AppendData(nil, 0x1000, true, true)...)
+	if !bytes.Equal(bulk.Data, want) {
+		t.Fatal("bulk data is not the concatenation of its columns")
+	}
+	cols, err := bulk.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != 2 || cols[1].X != -1 || cols[1].Z != 5 {
+		t.Fatalf("Columns = %+v", cols)
+	}
+	if s := cols[1].Block(4, 200, 4); s != codec.BlockState(89, 0) {
+		t.Errorf("block = %#x, want glowstone", s)
+	}
+	if cols[1].Biomes[0] != 2 {
+		t.Errorf("biome = %d, want 2", cols[1].Biomes[0])
+	}
+
+	bulk.Data = bulk.Data[:len(bulk.Data)-1]
+	if _, err := bulk.Columns(); err == nil {
+		t.Error("Columns accepted truncated data")
+	}
+}
@@ -0,0 +1,9 @@
+package packet
+
+// The packet types in packets_gen.go are generated from minecraft-data's
+// protocol.json for 1.8. packets.json lists which packets are generated and
+// under which names. The checked-in protocol.json is an excerpt holding the
+// listed packets only. It uses the upstream names and layout, so the full
+// upstream file can replace it without changing packets.json.
+
+//go:generate go run ../cmd/packetgen -protocol ../data/pc/1.8/protocol.json -list packets.json -packets packets_gen.go -registry ../state/register_gen.go
@@ -0,0 +1,194 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+	"github.com/NaymDev/mcgotocol/chat"
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/proto"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// LegacyPingVersion identifies which pre-Netty client sent a server list
+// ping. The response format depends on it.
+type LegacyPingVersion uint8
+
+const (
+	// LegacyPingBeta is sent by Beta 1.8 - 1.3 clients (just 0xFE).
+	LegacyPingBeta LegacyPingVersion = iota
+	// LegacyPing14 is sent by 1.4 - 1.5 clients (0xFE 0x01).
+	LegacyPing14
+	// LegacyPing16 is sent by 1.6 clients (0xFE 0x01 0xFA MC|PingHost ...).
+	LegacyPing16
+)
+
+const (
+	LegacyPingID = 0xFE
+	LegacyKickID = 0xFF
+
+	legacyPingHostChannel = "MC|PingHost"
+)
+
+var ErrInvalidLegacyPing = errors.New("invalid legacy server list ping")
+
+// LegacyServerListPing is not a framed packet: Connection returns it from
+// ReadPacket when the first bytes of a connection are a pre-Netty ping.
+// Hostname, Port and ProtocolVersion are only sent by 1.6 clients.
+type LegacyServerListPing struct {
+	Version         LegacyPingVersion
+	ProtocolVersion uint8
+	Hostname        string
+	Port            int32
+}
+
+var _ proto.Packet = (*LegacyServerListPing)(nil)
+
+func (l *LegacyServerListPing) ID() int32 {
+	return LegacyPingID
+}
+
+// Encode writes the 1.6 form of the ping including the leading 0xFE.
+func (l *LegacyServerListPing) Encode(writer io.Writer) error {
+	if _, err := writer.Write([]byte{LegacyPingID, 0x01, 0xFA}); err != nil {
+		return err
+	}
+	if err := writeLegacyString(writer, legacyPingHostChannel); err != nil {
+		return err
+	}
+	if err := codec.WriteShort(writer, int16(7+2*len(utf16.Encode([]rune(l.Hostname))))); err != nil {
+		return err
+	}
+	if err := codec.WriteUByte(writer, l.ProtocolVersion); err != nil {
+		return err
+	}
+	if err := writeLegacyString(writer, l.Hostname); err != nil {
+		return err
+	}
+	if err := codec.WriteInt(writer, l.Port); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Decode reads the 1.6 form of the ping following the leading 0xFE.
+func (l *LegacyServerListPing) Decode(reader io.Reader) error {
+	var marker [2]byte
+	if _, err := io.ReadFull(reader, marker[:]); err != nil {
+		return err
+	}
+	if marker != [2]byte{0x01, 0xFA} {
+		return ErrInvalidLegacyPing
+	}
+	channel, err := readLegacyString(reader)
+	if err != nil {
+		return err
+	}
+	if channel != legacyPingHostChannel {
+		return fmt.Errorf("%w: unexpected channel %q", ErrInvalidLegacyPing, channel)
+	}
+	if _, err = codec.ReadShort(reader); err != nil {
+		return err
+	}
+	if l.ProtocolVersion, err = codec.ReadUByte(reader); err != nil {
+		return err
+	}
+	if l.Hostname, err = readLegacyString(reader); err != nil {
+		return err
+	}
+	if l.Port, err = codec.ReadInt(reader); err != nil {
+		return err
+	}
+	l.Version = LegacyPing16
+	return nil
+}
+
+// LegacyServerListPong is the 0xFF kick-style answer to a legacy ping.
+type LegacyServerListPong struct {
+	ProtocolVersion int32
+	VersionName     string
+	MOTD            string
+	OnlinePlayers   int
+	MaxPlayers      int
+}
+
+// Encode writes the response in the format understood by clients sending a
+// ping of the given version.
+func (l *LegacyServerListPong) Encode(writer io.Writer, version LegacyPingVersion) error {
+	var s string
+	if version == LegacyPingBeta {
+		// Beta separates the fields with §, so the MOTD has to be plain text
+		s = strings.Join([]string{
+			chat.StripLegacy(l.MOTD),
+			strconv.Itoa(l.OnlinePlayers),
+			strconv.Itoa(l.MaxPlayers),
+		}, "§")
+	} else {
+		s = strings.Join([]string{
+			"§1",
+			strconv.Itoa(int(l.ProtocolVersion)),
+			l.VersionName,
+			l.MOTD,
+			strconv.Itoa(l.OnlinePlayers),
+			strconv.Itoa(l.MaxPlayers),
+		}, "\x00")
+	}
+	if err := codec.WriteUByte(writer, LegacyKickID); err != nil {
+		return err
+	}
+	return writeLegacyString(writer, s)
+}
+
+// LegacyPongFromStatus builds the legacy response from the JSON used for
+// ClientStatusResponse. The description keeps its formatting as § codes,
+// which Encode strips for Beta clients.
+func LegacyPongFromStatus(jsonResponse string) (*LegacyServerListPong, error) {
+	status, err := (&ClientStatusResponse{JSONResponse: jsonResponse}).Status()
+	if err != nil {
+		return nil, err
+	}
+	return &LegacyServerListPong{
+		ProtocolVersion: status.Version.Protocol,
+		VersionName:     status.Version.Name,
+		MOTD:            status.Description.Legacy(),
+		OnlinePlayers:   status.Players.Online,
+		MaxPlayers:      status.Players.Max,
+	}, nil
+}
+
+// Legacy strings are prefixed with their length in UTF-16 code units and
+// encoded as UTF-16BE.
+func writeLegacyString(w io.Writer, s string) error {
+	units := utf16.Encode([]rune(s))
+	if err := codec.WriteShort(w, int16(len(units))); err != nil {
+		return err
+	}
+	buf := make([]byte, 2*len(units))
+	for i, u := range units {
+		buf[2*i] = byte(u >> 8)
+		buf[2*i+1] = byte(u)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func readLegacyString(r io.Reader) (string, error) {
+	length, err := codec.ReadShort(r)
+	if err != nil {
+		return "", err
+	}
+	if length < 0 {
+		return "", ErrInvalidLegacyPing
+	}
+	buf := make([]byte, 2*int(length))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	units := make([]uint16, length)
+	for i := range units {
+		units[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+	}
+	return string(utf16.Decode(units)), nil
+}
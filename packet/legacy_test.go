@@ -0,0 +1,37 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLegacyServerListPong(t *testing.T) {
+	pong := &LegacyServerListPong{
+		ProtocolVersion: 47,
+		VersionName:     "1.8.9",
+		MOTD:            "§aA §lMinecraft§r Server",
+		OnlinePlayers:   3,
+		MaxPlayers:      20,
+	}
+	tests := []struct {
+		version LegacyPingVersion
+		want    string
+	}{
+		{LegacyPingBeta, "A Minecraft Server§3§20"},
+		{LegacyPing14, "§1\x0047\x001.8.9\x00§aA §lMinecraft§r Server\x003\x0020"},
+		{LegacyPing16, "§1\x0047\x001.8.9\x00§aA §lMinecraft§r Server\x003\x0020"},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		if err := pong.Encode(buf, tt.version); err != nil {
+			t.Fatal(err)
+		}
+		if kick, _ := buf.ReadByte(); kick != LegacyKickID {
+			t.Fatalf("first byte = %#x, want 0xFF", kick)
+		}
+		got, err := readLegacyString(buf)
+		if err != nil || got != tt.want {
+			t.Errorf("version %d: %q, %v, want %q", tt.version, got, err, tt.want)
+		}
+	}
+}
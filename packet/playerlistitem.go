@@ -38,6 +38,14 @@ func FromProfileProperty(p profile.Property) Property {
 	}
 }
 
+func FromProfileProperties(props []profile.Property) []Property {
+	result := make([]Property, len(props))
+	for i, p := range props {
+		result[i] = FromProfileProperty(p)
+	}
+	return result
+}
+
 func (p *Property) Encode(w io.Writer) error {
 	if err := codec.WriteString(w, p.Name); err != nil {
 		return err
@@ -56,6 +64,32 @@ func (p *Property) Encode(w io.Writer) error {
 	return nil
 }
 
+func (p *Property) Decode(r io.Reader) error {
+	var err error
+	if p.Name, err = codec.ReadString(r); err != nil {
+		return err
+	}
+	if p.Value, err = codec.ReadString(r); err != nil {
+		return err
+	}
+	if p.IsSigned, err = codec.ReadBool(r); err != nil {
+		return err
+	}
+	p.Signature, p.Property.Signature = "", nil
+	if p.IsSigned {
+		if p.Signature, err = codec.ReadString(r); err != nil {
+			return err
+		}
+		// not &p.Signature, which would point into whichever copy of p
+		// was decoded
+		signature := p.Signature
+		p.Property.Signature = &signature
+	}
+	return nil
+}
+
+// PlayerProfile is laid out like an AddPlayer entry; the other actions only
+// use a subset of the fields.
 type PlayerProfile struct {
 	UUID           uuid.UUID
 	Name           string
@@ -63,7 +97,12 @@ type PlayerProfile struct {
 	Gamemode       codec.VarInt
 	Ping           codec.VarInt
 	HasDisplayName bool
-	DisplayName    *codec.Chat
+	DisplayName    *codec.Chat `mc:"optional=HasDisplayName"`
+}
+
+type displayNameUpdate struct {
+	HasDisplayName bool
+	DisplayName    *codec.Chat `mc:"optional=HasDisplayName"`
 }
 
 type ClientPlayerListItem struct {
@@ -84,37 +123,18 @@ func (c *ClientPlayerListItem) Encode(writer io.Writer) error {
 	if err := codec.WriteVarInt(writer, codec.VarInt(len(c.Players))); err != nil {
 		return err
 	}
-	for _, player := range c.Players {
+	for i := range c.Players {
+		player := &c.Players[i]
+		if c.Action == AddPlayer {
+			if err := codec.EncodeStruct(writer, player); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := codec.WriteUUID(writer, player.UUID); err != nil {
 			return err
 		}
 		switch c.Action {
-		case AddPlayer:
-			if err := codec.WriteString(writer, player.Name); err != nil {
-				return err
-			}
-			if err := codec.WriteVarInt(writer, codec.VarInt(len(player.Properties))); err != nil {
-				return err
-			}
-			for _, prop := range player.Properties {
-				if err := prop.Encode(writer); err != nil {
-					return err
-				}
-			}
-			if err := codec.WriteVarInt(writer, player.Gamemode); err != nil {
-				return err
-			}
-			if err := codec.WriteVarInt(writer, player.Ping); err != nil {
-				return err
-			}
-			if err := codec.WriteBool(writer, player.HasDisplayName); err != nil {
-				return err
-			}
-			if player.HasDisplayName {
-				if err := codec.WriteChat(writer, *player.DisplayName); err != nil {
-					return err
-				}
-			}
 		case UpdateGamemode:
 			if err := codec.WriteVarInt(writer, player.Gamemode); err != nil {
 				return err
@@ -124,14 +144,10 @@ func (c *ClientPlayerListItem) Encode(writer io.Writer) error {
 				return err
 			}
 		case UpdateDisplayName:
-			if err := codec.WriteBool(writer, player.HasDisplayName); err != nil {
+			update := displayNameUpdate{player.HasDisplayName, player.DisplayName}
+			if err := codec.EncodeStruct(writer, &update); err != nil {
 				return err
 			}
-			if player.HasDisplayName {
-				if err := codec.WriteChat(writer, *player.DisplayName); err != nil {
-					return err
-				}
-			}
 		case RemovePlayer:
 		}
 	}
@@ -149,102 +165,40 @@ func (c *ClientPlayerListItem) Decode(reader io.Reader) error {
 	if err != nil {
 		return err
 	}
+	if playerCount < 0 {
+		return codec.ErrInvalidLength
+	}
 
 	c.Players = make([]PlayerProfile, playerCount)
 
-	for i := 0; i < int(playerCount); i++ {
-		player := PlayerProfile{}
-
-		u, err := codec.ReadUUID(reader)
-		if err != nil {
+	for i := range c.Players {
+		player := &c.Players[i]
+		if c.Action == AddPlayer {
+			if err := codec.DecodeStruct(reader, player); err != nil {
+				return err
+			}
+			continue
+		}
+		if player.UUID, err = codec.ReadUUID(reader); err != nil {
 			return err
 		}
-		player.UUID = u
-
 		switch c.Action {
-		case AddPlayer:
-			name, err := codec.ReadString(reader)
-			if err != nil {
-				return err
-			}
-			player.Name = name
-
-			propCount, err := codec.ReadVarInt(reader)
-			if err != nil {
-				return err
-			}
-			player.Properties = make([]Property, propCount)
-			for j := 0; j < int(propCount); j++ {
-				prop := Property{}
-				prop.Name, err = codec.ReadString(reader)
-				if err != nil {
-					return err
-				}
-				prop.Value, err = codec.ReadString(reader)
-				if err != nil {
-					return err
-				}
-				prop.IsSigned, err = codec.ReadBool(reader)
-				if err != nil {
-					return err
-				}
-				if prop.IsSigned {
-					prop.Signature, err = codec.ReadString(reader)
-					if err != nil {
-						return err
-					}
-				}
-				player.Properties[j] = prop
-			}
-
-			player.Gamemode, err = codec.ReadVarInt(reader)
-			if err != nil {
-				return err
-			}
-
-			player.Ping, err = codec.ReadVarInt(reader)
-			if err != nil {
-				return err
-			}
-
-			player.HasDisplayName, err = codec.ReadBool(reader)
-			if err != nil {
-				return err
-			}
-			if player.HasDisplayName {
-				displayName, err := codec.ReadChat(reader)
-				if err != nil {
-					return err
-				}
-				player.DisplayName = &displayName
-			}
-
 		case UpdateGamemode:
-			player.Gamemode, err = codec.ReadVarInt(reader)
-			if err != nil {
+			if player.Gamemode, err = codec.ReadVarInt(reader); err != nil {
 				return err
 			}
 		case UpdateLatency:
-			player.Ping, err = codec.ReadVarInt(reader)
-			if err != nil {
+			if player.Ping, err = codec.ReadVarInt(reader); err != nil {
 				return err
 			}
 		case UpdateDisplayName:
-			player.HasDisplayName, err = codec.ReadBool(reader)
-			if err != nil {
+			var update displayNameUpdate
+			if err := codec.DecodeStruct(reader, &update); err != nil {
 				return err
 			}
-			if player.HasDisplayName {
-				displayName, err := codec.ReadChat(reader)
-				if err != nil {
-					return err
-				}
-				player.DisplayName = &displayName
-			}
+			player.HasDisplayName, player.DisplayName = update.HasDisplayName, update.DisplayName
 		case RemovePlayer:
 		}
-
-		c.Players[i] = player
 	}
 
 	return nil
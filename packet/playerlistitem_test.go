@@ -0,0 +1,42 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NaymDev/mcgotocol/codec"
+)
+
+func signedProperty(signature string) []byte {
+	b := codec.AppendString(nil, "textures")
+	b = codec.AppendString(b, "dGV4dHVyZXM=")
+	b = codec.AppendBool(b, true)
+	return codec.AppendString(b, signature)
+}
+
+func TestPropertyDecodeSignature(t *testing.T) {
+	var p Property
+	if err := p.Decode(bytes.NewReader(signedProperty("first"))); err != nil {
+		t.Fatal(err)
+	}
+	props := []Property{p}
+
+	// decoding into p again must not change the copy in props
+	if err := p.Decode(bytes.NewReader(signedProperty("second"))); err != nil {
+		t.Fatal(err)
+	}
+	if sig := props[0].Property.Signature; sig == nil || *sig != "first" {
+		t.Fatalf("signature of the copy = %v", sig)
+	}
+	if *p.Property.Signature != "second" {
+		t.Fatalf("signature = %q, want second", *p.Property.Signature)
+	}
+
+	unsigned := codec.AppendBool(codec.AppendString(codec.AppendString(nil, "a"), "b"), false)
+	if err := p.Decode(bytes.NewReader(unsigned)); err != nil {
+		t.Fatal(err)
+	}
+	if p.IsSigned || p.Property.Signature != nil {
+		t.Fatalf("unsigned property kept signature %v", p.Property.Signature)
+	}
+}
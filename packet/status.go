@@ -1,83 +1,47 @@
 package packet
 
 import (
-	"github.com/NaymDev/mcgotocol/codec"
-	"github.com/NaymDev/mcgotocol/proto"
-	"io"
+	"encoding/json"
+	"github.com/NaymDev/mcgotocol/chat"
 )
 
-type ServerStatusRequest struct{}
-
-var _ proto.Packet = (*ServerStatusRequest)(nil)
-
-func (s *ServerStatusRequest) ID() int32 {
-	return 0x00
-}
-
-func (s *ServerStatusRequest) Encode(writer io.Writer) error {
-	return nil
-}
-
-func (s *ServerStatusRequest) Decode(reader io.Reader) error {
-	return nil
+type StatusVersion struct {
+	Name     string `json:"name"`
+	Protocol int32  `json:"protocol"`
 }
 
-type ClientStatusResponse struct {
-	JSONResponse string
+type StatusPlayer struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
 }
 
-var _ proto.Packet = (*ClientStatusResponse)(nil)
-
-func (c ClientStatusResponse) ID() int32 {
-	return 0x00
-}
-
-func (c ClientStatusResponse) Encode(writer io.Writer) error {
-	return codec.WriteString(writer, c.JSONResponse)
+type StatusPlayers struct {
+	Max    int            `json:"max"`
+	Online int            `json:"online"`
+	Sample []StatusPlayer `json:"sample,omitempty"`
 }
 
-func (c ClientStatusResponse) Decode(reader io.Reader) error {
-	var err error
-	c.JSONResponse, err = codec.ReadString(reader)
-	return err
-}
-
-type ServerStatusPing struct {
-	Payload int64
-}
-
-var _ proto.Packet = (*ServerStatusPing)(nil)
-
-func (s *ServerStatusPing) ID() int32 {
-	return 0x01
-}
-
-func (s *ServerStatusPing) Encode(writer io.Writer) error {
-	return codec.WriteLong(writer, s.Payload)
-}
-
-func (s *ServerStatusPing) Decode(reader io.Reader) error {
-	var err error
-	s.Payload, err = codec.ReadLong(reader)
-	return err
-}
-
-type ClientStatusPong struct {
-	Payload int64
-}
-
-var _ proto.Packet = (*ClientStatusPong)(nil)
-
-func (c *ClientStatusPong) ID() int32 {
-	return 0x01
+// Status is the document sent in ClientStatusResponse. Favicon is a
+// "data:image/png;base64,..." URI of a 64x64 PNG.
+type Status struct {
+	Version     StatusVersion  `json:"version"`
+	Players     StatusPlayers  `json:"players"`
+	Description chat.Component `json:"description"`
+	Favicon     string         `json:"favicon,omitempty"`
 }
 
-func (c *ClientStatusPong) Encode(writer io.Writer) error {
-	return codec.WriteLong(writer, c.Payload)
+func NewClientStatusResponse(status Status) (*ClientStatusResponse, error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientStatusResponse{JSONResponse: string(data)}, nil
 }
 
-func (c *ClientStatusPong) Decode(reader io.Reader) error {
-	var err error
-	c.Payload, err = codec.ReadLong(reader)
-	return err
+func (c *ClientStatusResponse) Status() (*Status, error) {
+	var status Status
+	if err := json.Unmarshal([]byte(c.JSONResponse), &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
 }
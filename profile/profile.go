@@ -2,12 +2,19 @@ package profile
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
+const SessionServerURL = "https://sessionserver.mojang.com"
+
+var ErrNotAuthenticated = errors.New("player has not joined with this server hash")
+
 type Property struct {
 	Name      string  `json:"name"`
 	Value     string  `json:"value"`
@@ -50,14 +57,45 @@ func GetUUID(username string) (string, error) {
 	return result.ID, nil
 }
 
+// Client talks to a session server. The zero value uses SessionServerURL and
+// an HTTP client with a 10 second timeout; tests can point BaseURL at an
+// httptest.Server.
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// DefaultClient is used by the package level functions.
+var DefaultClient = &Client{}
+
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/")
+	}
+	return SessionServerURL
+}
+
+func (c *Client) get(url string) (*http.Response, error) {
+	client := c.HTTP
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	return client.Get(url)
+}
+
 func FetchProfileRaw(uuid string, unsigned bool) (*PlayerProfileRaw, error) {
-	url := fmt.Sprintf("https://sessionserver.mojang.com/session/minecraft/profile/%s", uuid)
+	return DefaultClient.FetchProfileRaw(uuid, unsigned)
+}
+
+func (c *Client) FetchProfileRaw(uuid string, unsigned bool) (*PlayerProfileRaw, error) {
+	url := fmt.Sprintf("%s/session/minecraft/profile/%s", c.baseURL(), uuid)
 	if !unsigned {
 		url += "?unsigned=false"
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	resp, err := c.get(url)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching profile: %w", err)
 	}
@@ -79,3 +117,47 @@ func FetchProfileRaw(uuid string, unsigned bool) (*PlayerProfileRaw, error) {
 
 	return &profile, nil
 }
+
+// HasJoined calls DefaultClient.HasJoined.
+func HasJoined(username, serverHash, ip string) (*PlayerProfileRaw, error) {
+	return DefaultClient.HasJoined(username, serverHash, ip)
+}
+
+// HasJoined asks the session server whether username authenticated against
+// serverHash (see encryption.ServerHash). On success the returned profile
+// contains the signed textures property. ip may be empty; if set the session
+// server additionally checks that the client joined from that address.
+func (c *Client) HasJoined(username, serverHash, ip string) (*PlayerProfileRaw, error) {
+	query := url.Values{}
+	query.Set("username", username)
+	query.Set("serverId", serverHash)
+	if ip != "" {
+		query.Set("ip", ip)
+	}
+	endpoint := fmt.Sprintf("%s/session/minecraft/hasJoined?%s", c.baseURL(), query.Encode())
+
+	resp, err := c.get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error verifying session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, ErrNotAuthenticated
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	var profile PlayerProfileRaw
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %w", err)
+	}
+
+	return &profile, nil
+}
@@ -0,0 +1,87 @@
+package profile
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const notchJoined = `{"id":"069a79f444e94726a5befca90e38aaf5","name":"Notch","properties":[{"name":"textures","value":"e30=","signature":"c2ln"}]}`
+
+func TestHasJoined(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		joined  bool
+		wantErr error // nil for errors other than ErrNotAuthenticated
+	}{
+		{"joined", http.StatusOK, notchJoined, true, nil},
+		{"not joined", http.StatusNoContent, "", false, ErrNotAuthenticated},
+		{"server error", http.StatusInternalServerError, "", false, nil},
+		{"bad json", http.StatusOK, "{", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/session/minecraft/hasJoined" {
+					t.Errorf("path = %s", r.URL.Path)
+				}
+				q := r.URL.Query()
+				if q.Get("username") != "Notch" || q.Get("serverId") != "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1" || q.Get("ip") != "127.0.0.1" {
+					t.Errorf("query = %v", q)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c := &Client{BaseURL: server.URL + "/", HTTP: server.Client()}
+			profile, err := c.HasJoined("Notch", "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1", "127.0.0.1")
+			if tt.joined {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if profile.ID != "069a79f444e94726a5befca90e38aaf5" || profile.Name != "Notch" || len(profile.Properties) != 1 ||
+					profile.Properties[0].Signature == nil || *profile.Properties[0].Signature != "c2ln" {
+					t.Errorf("profile = %+v", profile)
+				}
+				return
+			}
+			if err == nil || profile != nil {
+				t.Fatalf("HasJoined = %+v, %v, want an error", profile, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && errors.Is(err, ErrNotAuthenticated) {
+				t.Errorf("error = %v, want a server error", err)
+			}
+		})
+	}
+}
+
+func TestHasJoinedUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	if _, err := (&Client{BaseURL: server.URL}).HasJoined("Notch", "0", ""); err == nil {
+		t.Error("HasJoined on a closed server succeeded")
+	}
+}
+
+func TestFetchProfileRaw(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/minecraft/profile/069a79f444e94726a5befca90e38aaf5" || r.URL.Query().Get("unsigned") != "false" {
+			t.Errorf("URL = %s", r.URL)
+		}
+		w.Write([]byte(notchJoined))
+	}))
+	defer server.Close()
+
+	c := &Client{BaseURL: server.URL, HTTP: server.Client()}
+	profile, err := c.FetchProfileRaw("069a79f444e94726a5befca90e38aaf5", false)
+	if err != nil || profile.Name != "Notch" {
+		t.Errorf("FetchProfileRaw = %+v, %v", profile, err)
+	}
+}
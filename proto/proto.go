@@ -14,3 +14,28 @@ const (
 	ClientBound Direction = iota
 	ServerBound
 )
+
+// RawPacket carries the undecoded data of a packet. Encode writes Data
+// verbatim, so a RawPacket read from one connection can be forwarded
+// byte-for-byte to another.
+type RawPacket struct {
+	PacketID int32
+	Data     []byte
+}
+
+var _ Packet = (*RawPacket)(nil)
+
+func (p *RawPacket) ID() int32 {
+	return p.PacketID
+}
+
+func (p *RawPacket) Encode(writer io.Writer) error {
+	_, err := writer.Write(p.Data)
+	return err
+}
+
+func (p *RawPacket) Decode(reader io.Reader) error {
+	var err error
+	p.Data, err = io.ReadAll(reader)
+	return err
+}
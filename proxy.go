@@ -0,0 +1,32 @@
+package mcgotocol
+
+import (
+	"github.com/NaymDev/mcgotocol/proxyproto"
+	"net"
+)
+
+// ReadProxyHeader reads a PROXY protocol v1 or v2 header that a load balancer
+// put in front of the first Minecraft frame. It must be called before the
+// first ReadPacket. Connections whose peer is not in trusted are left
+// untouched so that direct clients cannot spoof their address; an empty
+// list trusts nobody, pass proxyproto.TrustAll to trust every peer. For
+// trusted peers the header is required.
+//
+// Afterwards RemoteAddr reports the original client address.
+func (c *Connection) ReadProxyHeader(trusted []*net.IPNet) error {
+	conn, ok := c.conn.(net.Conn)
+	if !ok || !proxyproto.Trusted(conn.RemoteAddr(), trusted) {
+		return nil
+	}
+	header, err := proxyproto.ReadHeader(c.reader)
+	if err != nil {
+		return err
+	}
+	c.proxyHeader = header
+	return nil
+}
+
+// ProxyHeader returns the header read by ReadProxyHeader, or nil.
+func (c *Connection) ProxyHeader() *proxyproto.Header {
+	return c.proxyHeader
+}
@@ -0,0 +1,279 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Command tells whether the connection was proxied on behalf of a client or
+// opened by the proxy itself (e.g. health checks).
+type Command uint8
+
+const (
+	CommandLocal Command = 0x0
+	CommandProxy Command = 0x1
+)
+
+// Common TLV types of version 2 headers.
+const (
+	TLVTypeALPN      byte = 0x01
+	TLVTypeAuthority byte = 0x02
+	TLVTypeCRC32C    byte = 0x03
+	TLVTypeNoop      byte = 0x04
+	TLVTypeUniqueID  byte = 0x05
+	TLVTypeSSL       byte = 0x20
+	TLVTypeNetNS     byte = 0x30
+)
+
+const (
+	v1MaxLength = 107
+	v2HeaderLen = 16
+)
+
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+var (
+	ErrNoProxyHeader = errors.New("no PROXY protocol header")
+	ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+)
+
+type TLV struct {
+	Type  byte
+	Value []byte
+}
+
+// Header is a parsed PROXY protocol header. Source and Destination are nil
+// for LOCAL connections and unknown or unsupported address families.
+type Header struct {
+	Version     int
+	Command     Command
+	Source      net.Addr
+	Destination net.Addr
+	TLVs        []TLV
+}
+
+// TLV returns the value of the first TLV of the given type.
+func (h *Header) TLV(typ byte) ([]byte, bool) {
+	for _, tlv := range h.TLVs {
+		if tlv.Type == typ {
+			return tlv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// ReadHeader reads a version 1 or 2 header from the start of r. If r does
+// not start with a header, ErrNoProxyHeader is returned and nothing is
+// consumed.
+func ReadHeader(r *bufio.Reader) (*Header, error) {
+	if sig, err := r.Peek(len(v2Signature)); err == nil && bytes.Equal(sig, v2Signature) {
+		return readV2(r)
+	}
+	if prefix, err := r.Peek(6); err == nil && string(prefix) == "PROXY " {
+		return readV1(r)
+	} else if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	return nil, ErrNoProxyHeader
+}
+
+func readV1(r *bufio.Reader) (*Header, error) {
+	var line []byte
+	for len(line) < v1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("%w: v1 line not terminated", ErrInvalidHeader)
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	h := &Header{Version: 1, Command: CommandProxy}
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return h, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("%w: malformed v1 line", ErrInvalidHeader)
+	}
+
+	src, err := parseV1Addr(fields[1], fields[2], fields[4])
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseV1Addr(fields[1], fields[3], fields[5])
+	if err != nil {
+		return nil, err
+	}
+	h.Source, h.Destination = src, dst
+	return h, nil
+}
+
+func parseV1Addr(proto, ip, port string) (*net.TCPAddr, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil || (proto == "TCP4") != (addr.To4() != nil) {
+		return nil, fmt.Errorf("%w: bad address %q", ErrInvalidHeader, ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad port %q", ErrInvalidHeader, port)
+	}
+	return &net.TCPAddr{IP: addr, Port: int(p)}, nil
+}
+
+func readV2(r *bufio.Reader) (*Header, error) {
+	var head [v2HeaderLen]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	if head[12]>>4 != 2 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, head[12]>>4)
+	}
+	h := &Header{Version: 2, Command: Command(head[12] & 0x0F)}
+	if h.Command != CommandLocal && h.Command != CommandProxy {
+		return nil, fmt.Errorf("%w: unknown command %d", ErrInvalidHeader, h.Command)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(head[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	var addrLen int
+	switch head[13] >> 4 {
+	case 0x1: // AF_INET
+		addrLen = 12
+		if len(payload) < addrLen {
+			return nil, fmt.Errorf("%w: short IPv4 address block", ErrInvalidHeader)
+		}
+		h.Source, h.Destination = v2Addrs(head[13], payload[0:4], payload[4:8], payload[8:12])
+	case 0x2: // AF_INET6
+		addrLen = 36
+		if len(payload) < addrLen {
+			return nil, fmt.Errorf("%w: short IPv6 address block", ErrInvalidHeader)
+		}
+		h.Source, h.Destination = v2Addrs(head[13], payload[0:16], payload[16:32], payload[32:36])
+	case 0x3: // AF_UNIX
+		addrLen = 216
+		if len(payload) < addrLen {
+			return nil, fmt.Errorf("%w: short unix address block", ErrInvalidHeader)
+		}
+		h.Source = &net.UnixAddr{Name: cString(payload[0:108]), Net: "unix"}
+		h.Destination = &net.UnixAddr{Name: cString(payload[108:216]), Net: "unix"}
+	}
+	if h.Command == CommandLocal {
+		h.Source, h.Destination = nil, nil
+	}
+
+	tlvs, err := parseTLVs(payload[addrLen:])
+	if err != nil {
+		return nil, err
+	}
+	h.TLVs = tlvs
+	return h, nil
+}
+
+func v2Addrs(family byte, src, dst, ports []byte) (net.Addr, net.Addr) {
+	srcIP := net.IP(append([]byte(nil), src...))
+	dstIP := net.IP(append([]byte(nil), dst...))
+	srcPort := int(binary.BigEndian.Uint16(ports[0:2]))
+	dstPort := int(binary.BigEndian.Uint16(ports[2:4]))
+	if family&0x0F == 0x2 { // DGRAM
+		return &net.UDPAddr{IP: srcIP, Port: srcPort}, &net.UDPAddr{IP: dstIP, Port: dstPort}
+	}
+	return &net.TCPAddr{IP: srcIP, Port: srcPort}, &net.TCPAddr{IP: dstIP, Port: dstPort}
+}
+
+func parseTLVs(data []byte) ([]TLV, error) {
+	var tlvs []TLV
+	for len(data) > 0 {
+		if len(data) < 3 {
+			return nil, fmt.Errorf("%w: truncated TLV", ErrInvalidHeader)
+		}
+		length := int(binary.BigEndian.Uint16(data[1:3]))
+		if len(data) < 3+length {
+			return nil, fmt.Errorf("%w: truncated TLV", ErrInvalidHeader)
+		}
+		tlvs = append(tlvs, TLV{Type: data[0], Value: data[3 : 3+length]})
+		data = data[3+length:]
+	}
+	return tlvs, nil
+}
+
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// ParseCIDRs is a helper to build the trusted upstream list from strings
+// like "10.0.0.0/8". Plain IPs are treated as single-host networks.
+func ParseCIDRs(cidrs ...string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %q", cidr)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// TrustAll trusts every peer, including non-IP ones such as Unix sockets.
+// Only use it when the listener cannot be reached except through the proxy.
+var TrustAll = []*net.IPNet{
+	{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)},
+	{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)},
+}
+
+// Trusted reports whether addr is contained in one of the networks. An empty
+// list trusts nobody; a /0 network such as those in TrustAll trusts
+// everyone.
+func Trusted(addr net.Addr, trusted []*net.IPNet) bool {
+	for _, n := range trusted {
+		if ones, _ := n.Mask.Size(); ones == 0 {
+			return true
+		}
+	}
+	var ip net.IP
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip = a.IP
+	case *net.UDPAddr:
+		ip = a.IP
+	default:
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package proxyproto
+
+import (
+	"net"
+	"testing"
+)
+
+func TestTrusted(t *testing.T) {
+	lan, err := ParseCIDRs("10.0.0.0/8", "::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v4 := &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 1}
+	other := &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 1}
+	v6 := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 1}
+	unix := &net.UnixAddr{Name: "/tmp/mc.sock", Net: "unix"}
+
+	tests := []struct {
+		name    string
+		addr    net.Addr
+		trusted []*net.IPNet
+		want    bool
+	}{
+		{"nil list", v4, nil, false},
+		{"empty list", v4, []*net.IPNet{}, false},
+		{"in network", v4, lan, true},
+		{"outside network", other, lan, false},
+		{"single v6 host", v6, lan, true},
+		{"trust all v4", other, TrustAll, true},
+		{"trust all v6", v6, TrustAll, true},
+		{"trust all unix", unix, TrustAll, true},
+		{"unix not in list", unix, lan, false},
+	}
+	for _, tt := range tests {
+		if got := Trusted(tt.addr, tt.trusted); got != tt.want {
+			t.Errorf("%s: Trusted = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package mcgotocol
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// OverflowPolicy decides what WritePacket does when the outbound queue of a
+// connection is full.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock waits until the writer goroutine made room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop discards the packet and returns ErrPacketDropped.
+	OverflowDrop
+	// OverflowDisconnect closes the connection and returns ErrSlowConsumer.
+	OverflowDisconnect
+)
+
+var (
+	ErrPacketDropped = errors.New("write queue full, packet dropped")
+	ErrSlowConsumer  = errors.New("write queue full, connection closed")
+	ErrQueueClosed   = errors.New("write queue closed")
+)
+
+type queuedFrame struct {
+	frame   []byte
+	flushed chan struct{}
+}
+
+// writeQueue is drained by a single goroutine so frames of concurrent
+// writers can never interleave on the wire.
+type writeQueue struct {
+	frames chan queuedFrame
+	policy OverflowPolicy
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+	err    error
+}
+
+// StartWriteQueue makes all following writes asynchronous: encoded frames are
+// put into a queue of the given size and written by a dedicated goroutine.
+// Use Flush to wait until everything queued so far reached the socket.
+// It should be called before the connection is shared between goroutines;
+// calling it more than once has no effect.
+func (c *Connection) StartWriteQueue(size int, policy OverflowPolicy) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.queue != nil {
+		return
+	}
+	q := &writeQueue{
+		frames: make(chan queuedFrame, size),
+		policy: policy,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	c.queue = q
+	go c.drainQueue(q)
+}
+
+func (c *Connection) drainQueue(q *writeQueue) {
+	defer close(q.done)
+	for {
+		select {
+		case f := <-q.frames:
+			if f.flushed != nil {
+				close(f.flushed)
+				continue
+			}
+			if err := c.writeFrame(context.Background(), f.frame); err != nil {
+				q.err = err
+				return
+			}
+		case <-q.stop:
+			q.err = ErrQueueClosed
+			return
+		}
+	}
+}
+
+// push must be called with writeMu held. ctx is only honoured by
+// OverflowBlock.
+func (c *Connection) push(ctx context.Context, q *writeQueue, frame []byte) error {
+	f := queuedFrame{frame: frame}
+	switch q.policy {
+	case OverflowDrop:
+		select {
+		case q.frames <- f:
+			return nil
+		case <-q.done:
+			return q.err
+		default:
+			return ErrPacketDropped
+		}
+	case OverflowDisconnect:
+		select {
+		case q.frames <- f:
+			return nil
+		case <-q.done:
+			return q.err
+		default:
+			c.Close()
+			return ErrSlowConsumer
+		}
+	}
+	select {
+	case q.frames <- f:
+		return nil
+	case <-q.done:
+		return q.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush blocks until every packet written before the call has been handed to
+// the underlying connection. Without a write queue it returns immediately.
+func (c *Connection) Flush() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.flushLocked()
+}
+
+func (c *Connection) flushLocked() error {
+	q := c.queue
+	if q == nil {
+		return nil
+	}
+	flushed := make(chan struct{})
+	select {
+	case q.frames <- queuedFrame{flushed: flushed}:
+	case <-q.done:
+		return q.err
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-q.done:
+		return q.err
+	}
+}
+
+func (q *writeQueue) close() {
+	q.once.Do(func() {
+		close(q.stop)
+	})
+}
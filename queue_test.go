@@ -0,0 +1,188 @@
+package mcgotocol
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NaymDev/mcgotocol/codec"
+	"github.com/NaymDev/mcgotocol/encryption"
+	"github.com/NaymDev/mcgotocol/packet"
+	"github.com/NaymDev/mcgotocol/proto"
+	"github.com/NaymDev/mcgotocol/state"
+)
+
+// gatedConn holds every Write until release is closed.
+type gatedConn struct {
+	writing chan struct{} // closed when the first Write starts
+	release chan struct{}
+	once    sync.Once
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func newGatedConn() *gatedConn {
+	return &gatedConn{writing: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gatedConn) Read([]byte) (int, error) {
+	<-g.release
+	return 0, errors.New("gatedConn: no data")
+}
+
+func (g *gatedConn) Write(b []byte) (int, error) {
+	g.once.Do(func() { close(g.writing) })
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(b)
+}
+
+func (g *gatedConn) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closed = true
+	return nil
+}
+
+func (g *gatedConn) bytes() []byte {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]byte(nil), g.buf.Bytes()...)
+}
+
+func keepAlive(id codec.VarInt) proto.Packet {
+	return &packet.ClientKeepAlive{KeepAliveID: id}
+}
+
+func frames(t *testing.T, packets ...proto.Packet) []byte {
+	t.Helper()
+	var out []byte
+	for _, p := range packets {
+		frame, err := codec.MarshalPacket(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, frame...)
+	}
+	return out
+}
+
+// fillQueue starts a write queue of size 1 and fills it: the first packet is
+// stuck in Write, the second waits in the queue.
+func fillQueue(t *testing.T, policy OverflowPolicy) (*Connection, *gatedConn) {
+	t.Helper()
+	state.InitRegistries()
+	conn := newGatedConn()
+	c := NewConnection(conn, state.Play)
+	c.StartWriteQueue(1, policy)
+	if err := c.WritePacket(keepAlive(1)); err != nil {
+		t.Fatal(err)
+	}
+	<-conn.writing
+	if err := c.WritePacket(keepAlive(2)); err != nil {
+		t.Fatal(err)
+	}
+	return c, conn
+}
+
+func TestQueueOverflowBlock(t *testing.T) {
+	c, conn := fillQueue(t, OverflowBlock)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.WritePacketContext(ctx, keepAlive(3)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WritePacketContext on a full queue = %v, want DeadlineExceeded", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.WritePacket(keepAlive(4)) }()
+	close(conn.release)
+	if err := <-done; err != nil {
+		t.Fatalf("WritePacket after the queue drained = %v", err)
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := frames(t, keepAlive(1), keepAlive(2), keepAlive(4)); !bytes.Equal(conn.bytes(), want) {
+		t.Fatalf("wire = % x, want % x", conn.bytes(), want)
+	}
+}
+
+func TestQueueOverflowDrop(t *testing.T) {
+	c, conn := fillQueue(t, OverflowDrop)
+	defer c.Close()
+
+	if err := c.WritePacket(keepAlive(3)); !errors.Is(err, ErrPacketDropped) {
+		t.Fatalf("WritePacket on a full queue = %v, want ErrPacketDropped", err)
+	}
+	close(conn.release)
+	if err := c.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := frames(t, keepAlive(1), keepAlive(2)); !bytes.Equal(conn.bytes(), want) {
+		t.Fatalf("wire = % x, want % x", conn.bytes(), want)
+	}
+	if conn.closed {
+		t.Fatal("dropping a packet closed the connection")
+	}
+}
+
+func TestQueueOverflowDisconnect(t *testing.T) {
+	c, conn := fillQueue(t, OverflowDisconnect)
+	defer close(conn.release)
+
+	if err := c.WritePacket(keepAlive(3)); !errors.Is(err, ErrSlowConsumer) {
+		t.Fatalf("WritePacket on a full queue = %v, want ErrSlowConsumer", err)
+	}
+	conn.mu.Lock()
+	closed := conn.closed
+	conn.mu.Unlock()
+	if !closed {
+		t.Fatal("connection was not closed")
+	}
+	if err := c.WritePacket(keepAlive(4)); err == nil {
+		t.Fatal("WritePacket after the disconnect succeeded")
+	}
+}
+
+func TestEnableEncryptionFlushesQueue(t *testing.T) {
+	c, conn := fillQueue(t, OverflowBlock)
+	defer c.Close()
+
+	secret := bytes.Repeat([]byte{0x42}, encryption.SharedSecretSize)
+	done := make(chan error, 1)
+	go func() { done <- c.EnableEncryption(secret) }()
+	// the frames queued before have to be written before the cipher switch
+	close(conn.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WritePacket(keepAlive(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	plain := frames(t, keepAlive(1), keepAlive(2))
+	wire := conn.bytes()
+	if len(wire) < len(plain) || !bytes.Equal(wire[:len(plain)], plain) {
+		t.Fatalf("wire = % x, want the plain frames % x first", wire, plain)
+	}
+	_, decrypt, err := encryption.NewStreams(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := wire[len(plain):]
+	decrypt.XORKeyStream(encrypted, encrypted)
+	if want := frames(t, keepAlive(3)); !bytes.Equal(encrypted, want) {
+		t.Fatalf("decrypted = % x, want % x", encrypted, want)
+	}
+}
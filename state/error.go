@@ -12,3 +12,31 @@ var _ error = (*UnknownPacketID)(nil)
 func (e *UnknownPacketID) Error() string {
 	return fmt.Sprintf("unknown packet ID 0x%X (State: %s)", e.PacketID, e.State)
 }
+
+// UnexpectedPacket is returned when a packet is written in a state whose
+// registry does not contain it.
+type UnexpectedPacket struct {
+	PacketID int32
+	Type     string
+	State    string
+}
+
+var _ error = (*UnexpectedPacket)(nil)
+
+func (e *UnexpectedPacket) Error() string {
+	return fmt.Sprintf("packet %s (ID 0x%X) is not valid in state %s", e.Type, e.PacketID, e.State)
+}
+
+// TrailingBytes is returned in strict mode when a packet's Decode did not
+// consume the whole frame.
+type TrailingBytes struct {
+	PacketID  int32
+	State     string
+	Remaining int
+}
+
+var _ error = (*TrailingBytes)(nil)
+
+func (e *TrailingBytes) Error() string {
+	return fmt.Sprintf("%d unread bytes after packet ID 0x%X (State: %s)", e.Remaining, e.PacketID, e.State)
+}
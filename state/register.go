@@ -1,7 +1,6 @@
 package state
 
 import (
-	"github.com/NaymDev/mcgotocol/packet"
 	"github.com/NaymDev/mcgotocol/state/states"
 )
 
@@ -12,30 +11,17 @@ var (
 	Play      = NewRegistry(states.PlayState)
 )
 
-func InitRegistries() {
-	// HANDSHAKE
-	Handshake.ServerBound.Register(&packet.ServerHandshake{})
-
-	// STATUS
-	Status.ServerBound.Register(&packet.ServerStatusRequest{})
-	Status.ServerBound.Register(&packet.ServerStatusPing{})
-
-	Status.ClientBound.Register(&packet.ClientStatusResponse{})
-	Status.ClientBound.Register(&packet.ClientStatusPong{})
-
-	// LOGIN
-	Login.ServerBound.Register(&packet.ServerLoginStart{})
-
-	Login.ClientBound.Register(&packet.ClientLoginSuccess{})
-
-	// PLAY
-	Play.ServerBound.Register(&packet.ServerKeepAlive{})
-
-	Play.ClientBound.Register(&packet.ClientKeepAlive{})
-	Play.ClientBound.Register(&packet.ClientJoinGame{})
-	Play.ClientBound.Register(&packet.ClientSetSpawnPosition{})
-	Play.ClientBound.Register(&packet.ClientPlayerPositionAndLook{})
-	Play.ClientBound.Register(&packet.ClientPlayerListItem{})
-	Play.ClientBound.Register(&packet.ClientSpawnPlayer{})
-	Play.ClientBound.Register(&packet.ClientPlayerAbilities{})
+// ForState returns the registry of s, or nil for an unknown state.
+func ForState(s states.State) *Registry {
+	switch s {
+	case states.HandshakeState:
+		return Handshake
+	case states.StatusState:
+		return Status
+	case states.LoginState:
+		return Login
+	case states.PlayState:
+		return Play
+	}
+	return nil
 }
@@ -30,6 +30,7 @@ func NewRegistry(state states.State) *Registry {
 type PacketRegistry struct {
 	State string
 	ctors [MaxPacketID]Constructor
+	types [MaxPacketID]reflect.Type
 }
 type Constructor func() proto.Packet
 
@@ -41,12 +42,26 @@ func (r *PacketRegistry) Register(packet proto.Packet) {
 		t = t.Elem()
 	}
 
+	r.types[id] = t
 	r.ctors[id] = func() proto.Packet {
 		v := reflect.New(t)
 		return v.Interface().(proto.Packet)
 	}
 }
 
+// Contains reports whether packet is the type registered for its ID.
+func (r *PacketRegistry) Contains(packet proto.Packet) bool {
+	id := packet.ID()
+	if id < 0 || int(id) >= MaxPacketID || r.types[id] == nil {
+		return false
+	}
+	t := reflect.TypeOf(packet)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return r.types[id] == t
+}
+
 func (r *PacketRegistry) Decode(id int32, reader io.Reader) (proto.Packet, error) {
 	if id < 0 || int(id) >= MaxPacketID {
 		return nil, &UnknownPacketID{